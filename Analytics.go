@@ -0,0 +1,252 @@
+package podcast
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMinDownloadBytes - bytes required for a download when episode bitrate is unknown
+const DefaultMinDownloadBytes = 1 << 20
+
+// Requests of the same client are counted as one download within this window
+const downloadWindow = 24 * time.Hour
+
+// nginx/apache combined (or common) log format
+var reAccessLogLine = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "(\S+) (\S+)[^"]*" (\d{3}) (\d+|-)(?: "[^"]*" "([^"]*)")?`)
+
+// Analytics - counts episode downloads similar to IAB Podcast Measurement guidelines:
+//   - requests of bots are ignored
+//   - requests of the same IP and user agent are counted once per 24 hours
+//   - at least 1 minute of audio (by `Item.Bitrate`) or `MinBytes` must be downloaded within 24 hours
+//
+// Use as `EnclosureHandler.Sink` or read access logs with `ReadAccessLog`.
+type Analytics struct {
+	// MinBytes - bytes required for a download. By bitrate of episode if 0
+	MinBytes int64
+
+	// UserAgents - detects apps and bots. Embedded rules by default.
+	// If nil app and bot of event are used
+	UserAgents *UserAgentClassifier
+
+	mu      sync.Mutex
+	items   map[string]*Item // by enclosure URL path
+	windows map[string]*downloadWindowState
+	pruned  time.Time // windows older than 24 hours removed
+	counts  map[AnalyticsRow]int
+}
+
+// Requests of one client for one episode
+type downloadWindowState struct {
+	start   time.Time
+	bytes   int64
+	counted bool
+}
+
+// AnalyticsRow - downloads of episode in one day by one app
+type AnalyticsRow struct {
+	Date      string `json:"date"` // UTC `2006-01-02`
+	Key       string `json:"key"`
+	App       string `json:"app"`
+	Downloads int    `json:"downloads"`
+}
+
+// AnalyticsReport - downloads per episode, day and app
+type AnalyticsReport struct {
+	Downloads int            `json:"downloads"`
+	Episodes  map[string]int `json:"episodes"` // by item key
+	Rows      []AnalyticsRow `json:"rows"`
+}
+
+// NewAnalytics - download counter for episodes of `podcast`
+func NewAnalytics(podcast *Podcast) *Analytics {
+	a := &Analytics{
+		UserAgents: DefaultUserAgentClassifier(),
+		items:      map[string]*Item{},
+		windows:    map[string]*downloadWindowState{},
+		counts:     map[AnalyticsRow]int{},
+	}
+
+	for _, item := range podcast.Feed.Channel.Items {
+		if p := enclosurePath(item); p != "" {
+			a.items[p] = item
+		}
+	}
+
+	return a
+}
+
+// Download - count download event. Events must come in time order
+func (a *Analytics) Download(event DownloadEvent) {
+	if event.Status != 200 && event.Status != 206 {
+		return
+	}
+
+	client := UserAgentInfo{App: event.App, Bot: event.Bot}
+	if a.UserAgents != nil {
+		client = a.UserAgents.Classify(event.UserAgent)
+	}
+	if client.Bot {
+		return
+	}
+	if client.App == "" {
+		client.App = "Other"
+	}
+
+	u, err := url.Parse(event.URL)
+	if err != nil {
+		return
+	}
+	item := a.items[cleanURLPath(u.Path)]
+	if item == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if event.Time.Sub(a.pruned) >= downloadWindow {
+		for key, w := range a.windows {
+			if event.Time.Sub(w.start) >= downloadWindow {
+				delete(a.windows, key)
+			}
+		}
+		a.pruned = event.Time
+	}
+
+	key := event.IP + "\n" + event.UserAgent + "\n" + item.Key
+	w := a.windows[key]
+	if w == nil || event.Time.Sub(w.start) >= downloadWindow {
+		w = &downloadWindowState{start: event.Time}
+		a.windows[key] = w
+	}
+
+	w.bytes += event.Bytes
+	if w.counted || w.bytes < a.minBytes(item) {
+		return
+	}
+	w.counted = true
+
+	row := AnalyticsRow{
+		Date: w.start.UTC().Format("2006-01-02"),
+		Key:  item.Key,
+		App:  client.App,
+	}
+	a.counts[row]++
+}
+
+// Bytes required for download of `item`
+func (a *Analytics) minBytes(item *Item) int64 {
+	if a.MinBytes > 0 {
+		return a.MinBytes
+	}
+	if item.Bitrate > 0 {
+		return int64(item.Bitrate) * 1000 / 8 * 60 // 1 minute
+	}
+	return DefaultMinDownloadBytes
+}
+
+// ReadAccessLog - count downloads from nginx/apache access log in combined log format.
+// Lines not matching format are skipped.
+func (a *Analytics) ReadAccessLog(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	skipped := 0
+	for scanner.Scan() {
+		event, ok := parseAccessLogLine(scanner.Text())
+		if !ok {
+			skipped++
+			continue
+		}
+		a.Download(event)
+	}
+
+	if skipped > 0 {
+		log.Printf("Warning: %d access log lines skipped", skipped)
+	}
+
+	return scanner.Err()
+}
+
+// Download event from access log line. Only GET requests
+func parseAccessLogLine(line string) (DownloadEvent, bool) {
+	m := reAccessLogLine.FindStringSubmatch(line)
+	if m == nil || m[3] != "GET" {
+		return DownloadEvent{}, false
+	}
+
+	t, err := time.Parse("02/Jan/2006:15:04:05 -0700", m[2])
+	if err != nil {
+		return DownloadEvent{}, false
+	}
+
+	status, _ := strconv.Atoi(m[5])
+	bytes, _ := strconv.ParseInt(m[6], 10, 64)
+
+	return DownloadEvent{
+		Time:      t,
+		URL:       m[4],
+		Status:    status,
+		Bytes:     bytes,
+		UserAgent: m[7],
+		IP:        m[1],
+	}, true
+}
+
+// Report - downloads counted so far
+func (a *Analytics) Report() *AnalyticsReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	report := &AnalyticsReport{
+		Episodes: map[string]int{},
+		Rows:     []AnalyticsRow{},
+	}
+	for row, n := range a.counts {
+		row.Downloads = n
+		report.Rows = append(report.Rows, row)
+		report.Episodes[row.Key] += n
+		report.Downloads += n
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool {
+		ri, rj := report.Rows[i], report.Rows[j]
+		if ri.Date != rj.Date {
+			return ri.Date < rj.Date
+		}
+		if ri.Key != rj.Key {
+			return ri.Key < rj.Key
+		}
+		return ri.App < rj.App
+	})
+
+	return report
+}
+
+// WriteJSON - report as JSON
+func (report *AnalyticsReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// WriteCSV - report rows as CSV with header `date,key,app,downloads`
+func (report *AnalyticsReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "key", "app", "downloads"})
+	for _, row := range report.Rows {
+		cw.Write([]string{row.Date, row.Key, row.App, fmt.Sprint(row.Downloads)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
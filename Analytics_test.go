@@ -0,0 +1,87 @@
+package podcast
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnalyticsDownload(t *testing.T) {
+	p, err := New("podcast.yml", WithFS(newTestFS(testConfig)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	const (
+		url      = "https://example.xx/episodes/S01E01.mp3"
+		overcast = "Overcast/3.0 (+http://overcast.fm/; iOS podcast app)"
+	)
+	event := func(after time.Duration, ip, userAgent string, bytes int64) DownloadEvent {
+		return DownloadEvent{Time: start.Add(after), URL: url, Status: 200, Bytes: bytes, UserAgent: userAgent, IP: ip}
+	}
+
+	tests := []struct {
+		name      string
+		events    []DownloadEvent
+		noRules   bool // `UserAgents` nil
+		downloads int
+		app       string
+	}{
+		{"once", []DownloadEvent{event(0, "1.1.1.1", overcast, 200)}, false, 1, "Overcast"},
+		{"repeated in window", []DownloadEvent{
+			event(0, "1.1.1.1", overcast, 200),
+			event(time.Hour, "1.1.1.1", overcast, 200),
+			event(23*time.Hour, "1.1.1.1", overcast, 200),
+		}, false, 1, "Overcast"},
+		{"repeated after window", []DownloadEvent{
+			event(0, "1.1.1.1", overcast, 200),
+			event(25*time.Hour, "1.1.1.1", overcast, 200),
+		}, false, 2, "Overcast"},
+		{"other client", []DownloadEvent{
+			event(0, "1.1.1.1", overcast, 200),
+			event(time.Hour, "2.2.2.2", overcast, 200),
+		}, false, 2, "Overcast"},
+		{"bytes summed in window", []DownloadEvent{
+			event(0, "1.1.1.1", overcast, 60),
+			event(time.Hour, "1.1.1.1", overcast, 60),
+		}, false, 1, "Overcast"},
+		{"bytes not summed after window", []DownloadEvent{
+			event(0, "1.1.1.1", overcast, 60),
+			event(25*time.Hour, "1.1.1.1", overcast, 60),
+		}, false, 0, ""},
+		{"bot", []DownloadEvent{
+			event(0, "1.1.1.1", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", 200),
+			event(0, "1.1.1.1", "curl/7.68.0", 200),
+			event(0, "1.1.1.1", "", 200),
+		}, false, 0, ""},
+		{"unknown app", []DownloadEvent{event(0, "1.1.1.1", "SomePlayer/1.0", 200)}, false, 1, "Other"},
+		{"no rules", []DownloadEvent{event(0, "1.1.1.1", overcast, 200)}, true, 1, "Other"},
+		{"no rules app of event", []DownloadEvent{
+			{Time: start, URL: url, Status: 200, Bytes: 200, IP: "1.1.1.1", App: "Overcast"},
+			{Time: start, URL: url, Status: 200, Bytes: 200, IP: "2.2.2.2", Bot: true},
+		}, true, 1, "Overcast"},
+		{"not found", []DownloadEvent{{Time: start, URL: url, Status: 404, Bytes: 200, UserAgent: overcast, IP: "1.1.1.1"}}, false, 0, ""},
+		{"other file", []DownloadEvent{{Time: start, URL: "https://example.xx/other.mp3", Status: 200, Bytes: 200, UserAgent: overcast, IP: "1.1.1.1"}}, false, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAnalytics(p)
+			a.MinBytes = 100
+			if tt.noRules {
+				a.UserAgents = nil
+			}
+			for _, event := range tt.events {
+				a.Download(event)
+			}
+
+			report := a.Report()
+			if report.Downloads != tt.downloads {
+				t.Errorf("expected %d downloads, got %d", tt.downloads, report.Downloads)
+			}
+			if tt.app != "" && (len(report.Rows) == 0 || report.Rows[0].App != tt.app) {
+				t.Errorf("expected app `%s`, got %+v", tt.app, report.Rows)
+			}
+		})
+	}
+}
@@ -14,8 +14,7 @@ func (href *AttrHref) IsEmpty() bool {
 
 // UnmarshalYAML ..
 func (href *AttrHref) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	unmarshal(&href.Href)
-	return nil
+	return unmarshal(&href.Href)
 }
 
 // String return URL or Href
@@ -7,8 +7,7 @@ type CDATA struct {
 
 // UnmarshalYAML ..
 func (cdata *CDATA) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	unmarshal(&cdata.Text)
-	return nil
+	return unmarshal(&cdata.Text)
 }
 
 // IsEmpty ..
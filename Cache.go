@@ -0,0 +1,157 @@
+package podcast
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"sync"
+)
+
+// CacheFileName - default media cache file saved next to config file
+const CacheFileName = ".podcast-cache.json"
+
+// MediaCache - probe results of media files kept between builds.
+// Entry is valid while file size and modification time are the same.
+// With content hash enabled entry is also valid if file content is the same.
+type MediaCache struct {
+	Entries map[string]*MediaCacheEntry `json:"entries"`
+
+	fpath string
+	hash  bool
+
+	mu      sync.Mutex
+	used    map[string]bool
+	changed bool
+}
+
+// MediaCacheEntry - probe results of single media file
+type MediaCacheEntry struct {
+	Size     int64    `json:"size"`
+	ModTime  int64    `json:"mtime"`
+	Checksum string   `json:"checksum,omitempty"`
+	MimeType string   `json:"mimeType,omitempty"`
+	Duration Duration `json:"duration,omitempty"`
+	Bitrate  int      `json:"bitrate,omitempty"`
+}
+
+// LoadMediaCache - load cache from file. Missing or broken file gives empty cache
+func LoadMediaCache(fpath string, hash bool) *MediaCache {
+	cache := &MediaCache{
+		Entries: map[string]*MediaCacheEntry{},
+		fpath:   fpath,
+		hash:    hash,
+		used:    map[string]bool{},
+	}
+
+	buf, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(buf, cache); err != nil || cache.Entries == nil {
+		cache.Entries = map[string]*MediaCacheEntry{}
+	}
+
+	return cache
+}
+
+// Get - valid entry of file or nil
+func (cache *MediaCache) Get(fsys fs.FS, fpath string, info fs.FileInfo) *MediaCacheEntry {
+	if cache == nil {
+		return nil
+	}
+
+	cache.mu.Lock()
+	entry, ok := cache.Entries[fpath]
+	cache.mu.Unlock()
+
+	if !ok || entry.Size != info.Size() {
+		return nil
+	}
+
+	if entry.ModTime != info.ModTime().UnixNano() {
+		// file touched but content can be the same
+		if !cache.hash || entry.Checksum == "" {
+			return nil
+		}
+		if sum, err := fileChecksum(fsys, fpath); err != nil || sum != entry.Checksum {
+			return nil
+		}
+	}
+
+	cache.mu.Lock()
+	cache.used[fpath] = true
+	if modTime := info.ModTime().UnixNano(); entry.ModTime != modTime {
+		entry.ModTime = modTime
+		cache.changed = true
+	}
+	cache.mu.Unlock()
+
+	return entry
+}
+
+// Set - save probe results of file
+func (cache *MediaCache) Set(fpath string, entry *MediaCacheEntry) {
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.Entries[fpath] = entry
+	cache.used[fpath] = true
+	cache.changed = true
+}
+
+// Save cache to file if changed. Entries of files not used in this build are removed
+func (cache *MediaCache) Save() error {
+	if cache == nil || cache.fpath == "" {
+		return nil
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for fpath := range cache.Entries {
+		if !cache.used[fpath] {
+			delete(cache.Entries, fpath)
+			cache.changed = true
+		}
+	}
+	if !cache.changed {
+		return nil
+	}
+
+	buf, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = writeFileAtomic(cache.fpath, 0640, func(w io.Writer) error {
+		_, err := w.Write(buf)
+		return err
+	}, nil)
+	if err == nil {
+		cache.changed = false
+	}
+	return err
+}
+
+// SHA-256 of file content
+func fileChecksum(fsys fs.FS, fpath string) (string, error) {
+	f, err := openFile(fsys, fpath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,112 @@
+package podcast
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestMediaCacheGet(t *testing.T) {
+	modTime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := []byte("ID3 episode")
+	sum, err := fileChecksum(fstest.MapFS{"a.mp3": {Data: data}}, "a.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		file     *fstest.MapFile
+		hash     bool
+		expected bool
+	}{
+		{"same size and mtime", &fstest.MapFile{Data: data, ModTime: modTime}, false, true},
+		{"size changed", &fstest.MapFile{Data: []byte("ID3 longer episode"), ModTime: modTime}, false, false},
+		{"mtime changed", &fstest.MapFile{Data: data, ModTime: modTime.Add(time.Second)}, false, false},
+		{"mtime changed, same content", &fstest.MapFile{Data: data, ModTime: modTime.Add(time.Second)}, true, true},
+		{"mtime changed, other content", &fstest.MapFile{Data: []byte("ID3 epizode"), ModTime: modTime.Add(time.Second)}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := LoadMediaCache("", tt.hash)
+			cache.Entries["a.mp3"] = &MediaCacheEntry{Size: int64(len(data)), ModTime: modTime.UnixNano(), Checksum: sum, Bitrate: 128}
+
+			fsys := fstest.MapFS{"a.mp3": tt.file}
+			info, err := fs.Stat(fsys, "a.mp3")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entry := cache.Get(fsys, "a.mp3", info)
+			if (entry != nil) != tt.expected {
+				t.Fatalf("expected hit %v, got %+v", tt.expected, entry)
+			}
+			if entry != nil && entry.ModTime != tt.file.ModTime.UnixNano() {
+				t.Error("mtime of entry not updated")
+			}
+		})
+	}
+}
+
+func TestMediaCacheSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "podcast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modTime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"a.mp3": {Data: []byte("ID3 a"), ModTime: modTime},
+		"b.mp3": {Data: []byte("ID3 b"), ModTime: modTime},
+	}
+	fpath := filepath.Join(dir, CacheFileName)
+
+	// build probing all files
+	cache := LoadMediaCache(fpath, false)
+	for _, name := range []string{"a.mp3", "b.mp3"} {
+		cache.Set(name, &MediaCacheEntry{Size: 5, ModTime: modTime.UnixNano()})
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(cache *MediaCache, name string) *MediaCacheEntry {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cache.Get(fsys, name, info)
+	}
+
+	// nothing changed: file not written
+	cache = LoadMediaCache(fpath, false)
+	if get(cache, "a.mp3") == nil || get(cache, "b.mp3") == nil {
+		t.Fatal("saved entries not loaded")
+	}
+	if err := os.Remove(fpath); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(fpath); !os.IsNotExist(err) {
+		t.Error("unchanged cache saved")
+	}
+
+	// entry of removed file dropped
+	cache.used = map[string]bool{}
+	get(cache, "a.mp3")
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cache = LoadMediaCache(fpath, false)
+	if len(cache.Entries) != 1 || cache.Entries["a.mp3"] == nil {
+		t.Errorf("expected only entry of `a.mp3`, got %v", cache.Entries)
+	}
+}
@@ -0,0 +1,47 @@
+package podcast
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// SourceDateEpochEnv - environment variable with build time as Unix timestamp.
+// See https://reproducible-builds.org/specs/source-date-epoch/
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// Build time from `SOURCE_DATE_EPOCH` if set
+func sourceDateEpoch() (time.Time, bool) {
+	s := os.Getenv(SourceDateEpochEnv)
+	if s == "" {
+		return time.Time{}, false
+	}
+
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || sec < 0 {
+		log.Printf("Warning: Invalid %s `%s`. Current time used", SourceDateEpochEnv, s)
+		return time.Time{}, false
+	}
+
+	return time.Unix(sec, 0).UTC(), true
+}
+
+// Current time of build. `WithClock` or `SOURCE_DATE_EPOCH` if set
+func (channel *Channel) now() time.Time {
+	if channel != nil && channel.clock != nil {
+		return channel.clock()
+	}
+	return time.Now()
+}
+
+// Publish date of the newest item. Zero if no item has date
+func (items ItemList) newestPubDate() time.Time {
+	var newest time.Time
+	for _, item := range items {
+		if !item.PubDate.IsZero() && item.PubDate.After(newest) {
+			newest = item.PubDate.Time
+		}
+	}
+	return newest
+}
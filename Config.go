@@ -0,0 +1,593 @@
+package podcast
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// `${VAR}`, `${VAR:-default}` or escaped `$${VAR}`
+var reConfigVar = regexp.MustCompile(`\$(\$)?\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// YAML block scalar start `Description: |` or `- >-`
+var reBlockScalar = regexp.MustCompile(`(^|[:-])\s*[|>][-+0-9]*\s*$`)
+
+// `line 3: cannot unmarshal ..` ==> `cannot unmarshal ..`
+var reYAMLErrorLine = regexp.MustCompile(`^line \d+: `)
+
+// Keys of base config file to extend
+var configExtendsKeys = []string{"Extends", "extends"}
+
+// Config file formats
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+)
+
+// Format of config file by extension. YAML by default
+func configFormat(fpath string) string {
+	switch strings.ToLower(filepath.Ext(fpath)) {
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatYAML
+}
+
+// ConfigError - config value that can't be decoded
+type ConfigError struct {
+	File   string
+	Line   int
+	Column int
+	Path   string
+	Err    error
+}
+
+// Error - `podcast.yml:42:9: Items.S01E03.Duration: cannot parse "1h:20"`
+func (e *ConfigError) Error() string {
+	// no line numbers for TOML
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", e.File, e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Path, e.Err)
+}
+
+// ConfigErrors - all errors found in config
+type ConfigErrors []*ConfigError
+
+// Error - one error per line
+func (errs ConfigErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Decodes config files into structs.
+// Errors and warnings point to file, line and column of the value.
+type configDecoder struct {
+	fsys     fs.FS
+	files    map[*yaml.Node]string // file of every node
+	errors   ConfigErrors
+	warnings []string
+
+	// `Items:` given as pattern(s) of episode files, relative to config file declaring them
+	itemPatterns []string
+
+	// all files read and episode file patterns
+	sources        []string
+	sourcePatterns []string
+}
+
+func newConfigDecoder(fsys fs.FS) *configDecoder {
+	return &configDecoder{
+		fsys:  fsys,
+		files: map[*yaml.Node]string{},
+	}
+}
+
+// Read config file with variables resolved and base config files merged in.
+// Empty `format` is detected by file extension.
+// `seen` holds already read files to detect `Extends` loops.
+func (d *configDecoder) read(fpath, format string, seen []string) (*yaml.Node, error) {
+	absPath := fsPath(d.fsys, fpath)
+	if isOSFS(d.fsys) {
+		var err error
+		if absPath, err = filepath.Abs(fpath); err != nil {
+			return nil, err
+		}
+	}
+	if inSlice(absPath, seen) {
+		return nil, fmt.Errorf("%s: `Extends` loop: %s", fpath, strings.Join(append(seen, absPath), " -> "))
+	}
+	seen = append(seen, absPath)
+
+	buf, err := readFile(d.fsys, fpath)
+	if err != nil {
+		return nil, err
+	}
+	d.sources = append(d.sources, fpath)
+
+	buf, err = interpolate(buf, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", fpath, err)
+	}
+
+	if format == "" {
+		format = configFormat(fpath)
+	}
+
+	config, err := d.parse(fpath, buf, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extend base config if given
+	var basePath string
+	for i := 0; i+1 < len(config.Content); i += 2 {
+		key, value := config.Content[i], config.Content[i+1]
+
+		// JSON schema reference is not part of config
+		if key.Value == "$schema" {
+			config.Content = append(config.Content[:i], config.Content[i+2:]...)
+			i -= 2
+			continue
+		}
+
+		if !inSlice(key.Value, configExtendsKeys) {
+			continue
+		}
+
+		if value.Kind != yaml.ScalarNode || value.Value == "" {
+			return nil, d.newError(value, key.Value, fmt.Errorf("must be path to base config file"))
+		}
+		basePath = value.Value
+
+		config.Content = append(config.Content[:i], config.Content[i+2:]...)
+		i -= 2
+	}
+	if basePath == "" {
+		return config, nil
+	}
+
+	// relative to extending file
+	if !filepath.IsAbs(basePath) {
+		basePath = filepath.Join(filepath.Dir(fpath), basePath)
+	}
+
+	base, err := d.read(basePath, "", seen)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeConfig(base, config), nil
+}
+
+// Parse config into mapping node and remember file of all nodes.
+// JSON is parsed as YAML. TOML is converted into YAML nodes without line numbers.
+func (d *configDecoder) parse(fpath string, buf []byte, format string) (*yaml.Node, error) {
+	var doc yaml.Node
+
+	switch format {
+	case FormatYAML, FormatJSON:
+		if err := yaml.Unmarshal(buf, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %s", fpath, err)
+		}
+
+	case FormatTOML:
+		var m map[string]interface{}
+		if _, err := toml.Decode(string(buf), &m); err != nil {
+			return nil, fmt.Errorf("%s: %s", fpath, err)
+		}
+
+		var root yaml.Node
+		if err := root.Encode(m); err != nil {
+			return nil, fmt.Errorf("%s: %s", fpath, err)
+		}
+		doc.Content = []*yaml.Node{&root}
+
+	default:
+		return nil, fmt.Errorf("%s: unknown config format `%s`. Use one of %v", fpath, format, []string{FormatYAML, FormatJSON, FormatTOML})
+	}
+
+	// empty file
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Line: 1, Column: 1}, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s:%d:%d: config must be mapping of `Key: value`", fpath, root.Line, root.Column)
+	}
+
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		d.files[node] = fpath
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return root, nil
+}
+
+// Merge `config` values into `base`. Nested maps are merged, other values replaced.
+func mergeConfig(base, config *yaml.Node) *yaml.Node {
+	merged := *base
+	merged.Content = append([]*yaml.Node{}, base.Content...)
+
+	for i := 0; i+1 < len(config.Content); i += 2 {
+		key, value := config.Content[i], config.Content[i+1]
+
+		found := false
+		for j := 0; j+1 < len(merged.Content); j += 2 {
+			if merged.Content[j].Value != key.Value {
+				continue
+			}
+			found = true
+
+			baseValue := merged.Content[j+1]
+			if baseValue.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				merged.Content[j+1] = mergeConfig(baseValue, value)
+			} else {
+				merged.Content[j+1] = value
+			}
+			break
+		}
+
+		if !found {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+
+	return &merged
+}
+
+// Decode `node` into `v`. Errors are collected, unknown fields reported as warnings.
+// `path` is dotted path of the value in config (`Items.S01E03.Duration`)
+func (d *configDecoder) decode(node *yaml.Node, v reflect.Value, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.ShortTag() == "!!null" {
+		return
+	}
+
+	// items are decoded one by one to know the path of errors
+	if items, ok := v.Addr().Interface().(*ItemList); ok {
+		d.decodeItems(node, items, path)
+		return
+	}
+
+	switch v.Addr().Interface().(type) {
+	case yaml.Unmarshaler, interface {
+		UnmarshalYAML(func(interface{}) error) error
+	}:
+		d.decodeValue(node, v, path)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		d.decode(node, v.Elem(), path)
+
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			d.fail(node, path, fmt.Errorf("must be mapping of `Key: value`"))
+			return
+		}
+
+		// known fields by `yaml` tags, the same as in config schema
+		fields := yamlFields(v.Type())
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			index, ok := fields[key.Value]
+			if !ok {
+				d.warn(key, path, unknownFieldMessage(key.Value, fields))
+				continue
+			}
+			d.decode(value, v.Field(index), joinPath(path, key.Value))
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			d.fail(node, path, fmt.Errorf("must be mapping of `Key: value`"))
+			return
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			k := reflect.New(v.Type().Key())
+			if err := key.Decode(k.Interface()); err != nil {
+				// JSON and TOML keys are always strings: `"1"` ==> 1
+				resolved := *key
+				resolved.Tag, resolved.Style = "", 0
+				if resolved.Decode(k.Interface()) != nil {
+					d.fail(key, joinPath(path, key.Value), err)
+					continue
+				}
+			}
+
+			elem := reflect.New(v.Type().Elem())
+			d.decode(value, elem.Elem(), joinPath(path, key.Value))
+			v.SetMapIndex(k.Elem(), elem.Elem())
+		}
+
+	default:
+		d.decodeValue(node, v, path)
+	}
+}
+
+// Decode value with YAML decoder
+func (d *configDecoder) decodeValue(node *yaml.Node, v reflect.Value, path string) {
+	if err := node.Decode(v.Addr().Interface()); err != nil {
+		d.fail(node, path, err)
+	}
+}
+
+// Pattern of episode files relative to directory of config file it is declared in (base config too)
+func (d *configDecoder) itemPattern(node *yaml.Node) string {
+	if filepath.IsAbs(node.Value) {
+		return node.Value
+	}
+	return filepath.Join(filepath.Dir(d.files[node]), node.Value)
+}
+
+// Decode `Items:` as map of items by key or remember episode file pattern(s)
+func (d *configDecoder) decodeItems(node *yaml.Node, items *ItemList, path string) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		d.itemPatterns = append(d.itemPatterns, d.itemPattern(node))
+
+	case yaml.SequenceNode:
+		for _, pattern := range node.Content {
+			if pattern.Kind != yaml.ScalarNode {
+				d.fail(pattern, path, fmt.Errorf("must be pattern of episode files"))
+				continue
+			}
+			d.itemPatterns = append(d.itemPatterns, d.itemPattern(pattern))
+		}
+
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.ShortTag() == "!!null" {
+				continue
+			}
+
+			item := &Item{}
+			d.decode(value, reflect.ValueOf(item).Elem(), joinPath(path, key.Value))
+
+			item.Key = key.Value
+			item.ExtractKeyInfo()
+
+			*items = append(*items, item)
+		}
+
+	default:
+		d.fail(node, path, fmt.Errorf("must be mapping of items or pattern of episode files"))
+	}
+}
+
+// new error for value in `node`
+func (d *configDecoder) newError(node *yaml.Node, path string, err error) *ConfigError {
+	// `yaml: unmarshal errors: line 3: cannot unmarshal ..` ==> `cannot unmarshal ..`
+	if terr, ok := err.(*yaml.TypeError); ok {
+		msgs := make([]string, len(terr.Errors))
+		for i, msg := range terr.Errors {
+			msgs[i] = reYAMLErrorLine.ReplaceAllString(msg, "")
+		}
+		err = fmt.Errorf("%s", strings.Join(msgs, "; "))
+	}
+
+	return &ConfigError{
+		File:   d.files[node],
+		Line:   node.Line,
+		Column: node.Column,
+		Path:   path,
+		Err:    err,
+	}
+}
+
+// collect error
+func (d *configDecoder) fail(node *yaml.Node, path string, err error) {
+	d.errors = append(d.errors, d.newError(node, path, err))
+}
+
+// collect and log warning
+func (d *configDecoder) warn(node *yaml.Node, path, msg string) {
+	where := fmt.Sprintf("%s:%d:%d", d.files[node], node.Line, node.Column)
+	if node.Line == 0 {
+		where = d.files[node]
+	}
+	if path != "" {
+		where += ": " + path
+	}
+
+	warning := where + ": " + msg
+	d.warnings = append(d.warnings, warning)
+	log.Printf("Warning: %s", warning)
+}
+
+// Collected errors or nil
+func (d *configDecoder) err() error {
+	if len(d.errors) == 0 {
+		return nil
+	}
+	return d.errors
+}
+
+// YAML field names of struct and their index
+func yamlFields(t reflect.Type) map[string]int {
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = i
+	}
+	return fields
+}
+
+// `unknown field "Descripton", did you mean "Description"?`
+func unknownFieldMessage(name string, fields map[string]int) string {
+	msg := fmt.Sprintf("unknown field `%s`", name)
+
+	best, bestDist := "", 3
+	for field := range fields {
+		dist := levenshtein(strings.ToLower(name), strings.ToLower(field))
+		if dist < bestDist || (dist == bestDist && field < best) {
+			best, bestDist = field, dist
+		}
+	}
+	if best != "" {
+		msg += fmt.Sprintf(", did you mean `%s`?", best)
+	}
+
+	return msg
+}
+
+// `Items` + `S01E03` ==> `Items.S01E03`
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Replace `${VAR}` with value of variable and `${VAR:-default}` with default value if variable is empty or not set.
+// Escaped `$${VAR}` is left as `${VAR}`. Comments (YAML, TOML) are not changed.
+func interpolate(buf []byte, lookup func(string) (string, bool)) ([]byte, error) {
+	var undefined []string
+
+	block := -1          // indent of YAML block scalar key, its lines have no comments
+	var multiline []byte // open TOML multi-line string `"""` or `'''`
+
+	lines := bytes.Split(buf, []byte("\n"))
+	for n, line := range lines {
+		indent := len(line) - len(bytes.TrimLeft(line, " \t"))
+		if block >= 0 && indent <= block && len(bytes.TrimSpace(line)) > 0 {
+			block = -1
+		}
+
+		// TOML multi-line string opened or closed on this line
+		var delim []byte
+		for _, d := range [][]byte{[]byte(`"""`), []byte("'''")} {
+			if bytes.Count(line, d)%2 == 1 && (multiline == nil || bytes.Equal(multiline, d)) {
+				delim = d
+			}
+		}
+
+		var comment []byte
+		if block < 0 && multiline == nil && delim == nil {
+			if i := commentIndex(line); i >= 0 {
+				line, comment = line[:i], line[i:]
+			}
+			if reBlockScalar.Match(line) {
+				block = indent
+			}
+		} else if multiline != nil && delim != nil {
+			// comment after closing `"""`
+			end := bytes.LastIndex(line, delim) + len(delim)
+			if i := commentIndex(line[end:]); i >= 0 {
+				line, comment = line[:end+i], line[end+i:]
+			}
+		}
+		if delim != nil {
+			if multiline == nil {
+				multiline = delim
+			} else {
+				multiline = nil
+			}
+		}
+
+		line = reConfigVar.ReplaceAllFunc(line, func(match []byte) []byte {
+			m := reConfigVar.FindSubmatch(match)
+			if len(m[1]) > 0 {
+				return match[1:]
+			}
+
+			name := string(m[2])
+			if value, ok := lookup(name); ok && (value != "" || len(m[3]) == 0) {
+				return []byte(value)
+			}
+			if len(m[3]) > 0 {
+				return m[4]
+			}
+
+			undefined = append(undefined, fmt.Sprintf("`%s` (line %d)", name, n+1))
+			return match
+		})
+		lines[n] = append(line[:len(line):len(line)], comment...)
+	}
+
+	if len(undefined) > 0 {
+		return nil, fmt.Errorf("undefined variable %s", strings.Join(undefined, ", "))
+	}
+
+	return bytes.Join(lines, []byte("\n")), nil
+}
+
+// Start of `#` comment in config line or -1. Quoted values can contain `#`.
+// Quote starts value only after `:`, `=`, `-`, `,`, `[`, `{` or at line start,
+// so apostrophe in plain value (`Don't # ..`) is not a quote
+func commentIndex(line []byte) int {
+	var quote byte
+	prev := byte(' ') // the last non-space byte outside quotes
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+
+		if quote != 0 {
+			switch {
+			case c == '\\' && quote == '"':
+				i++
+			case c == quote && quote == '\'' && i+1 < len(line) && line[i+1] == '\'':
+				i++ // escaped `''`
+			case c == quote:
+				quote = 0
+				prev = c
+			}
+			continue
+		}
+
+		switch {
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return i
+		case (c == '"' || c == '\'') && strings.IndexByte(" :=-,[{", prev) >= 0:
+			quote = c
+		case c != ' ' && c != '\t':
+			prev = c
+		}
+	}
+	return -1
+}
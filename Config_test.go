@@ -0,0 +1,274 @@
+package podcast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestConfigErrorPositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  fstest.MapFS
+		config string
+		errors []string
+	}{
+		{
+			name: "item value",
+			files: fstest.MapFS{"podcast.yml": {Data: []byte(
+				"Title: Podcast\n" +
+					"Items:\n" +
+					"    S01E03:\n" +
+					"        Title: Third\n" +
+					"        Duration: 1h:20\n",
+			)}},
+			config: "podcast.yml",
+			errors: []string{"podcast.yml:5:19: Items.S01E03.Duration: "},
+		},
+		{
+			name: "all errors reported",
+			files: fstest.MapFS{"podcast.yml": {Data: []byte(
+				"Title: [Podcast]\n" +
+					"Seasons:\n" +
+					"    one:\n" +
+					"        Name: First\n" +
+					"Items:\n" +
+					"    S01E01:\n" +
+					"        Season: first\n",
+			)}},
+			config: "podcast.yml",
+			errors: []string{
+				"podcast.yml:1:8: Title: ",
+				"podcast.yml:3:5: Seasons.one: ",
+				"podcast.yml:7:17: Items.S01E01.Season: ",
+			},
+		},
+		{
+			name: "base config",
+			files: fstest.MapFS{
+				"base.yml":         {Data: []byte("Author: Neo\nSeasons:\n    1:\n        Explicit: [yes]\n")},
+				"show/podcast.yml": {Data: []byte("Extends: ../base.yml\nTitle: Podcast\n")},
+			},
+			config: "show/podcast.yml",
+			errors: []string{"base.yml:4:19: Seasons.1.Explicit: "},
+		},
+		{
+			name: "episode file",
+			files: fstest.MapFS{
+				"podcast.yml":         {Data: []byte("Title: Podcast\nItems: episodes/*.yml\n")},
+				"episodes/S01E01.yml": {Data: []byte("Title: First\nEpisode: [1]\n")},
+			},
+			config: "podcast.yml",
+			errors: []string{"episodes/S01E01.yml:2:10: Items.S01E01.Episode: "},
+		},
+		{
+			name:   "TOML without lines",
+			files:  fstest.MapFS{"podcast.toml": {Data: []byte("Title = \"Podcast\"\n[Items.S01E01]\nEpisode = \"one\"\n")}},
+			config: "podcast.toml",
+			errors: []string{"podcast.toml: Items.S01E01.Episode: "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.config, WithFS(tt.files))
+
+			var errs ConfigErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected ConfigErrors, got %v", err)
+			}
+			if len(errs) != len(tt.errors) {
+				t.Fatalf("expected %d errors, got:\n%s", len(tt.errors), err)
+			}
+			for i, prefix := range tt.errors {
+				if msg := errs[i].Error(); len(msg) < len(prefix) || msg[:len(prefix)] != prefix {
+					t.Errorf("expected error starting with `%s`, got `%s`", prefix, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigUnknownFieldWarning(t *testing.T) {
+	fsys := fstest.MapFS{"podcast.yml": {Data: []byte("Title: Podcast\nItems:\n    S01E01:\n        Titel: First\n")}}
+
+	decoder := newConfigDecoder(fsys)
+	root, err := decoder.read("podcast.yml", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder.decode(root, reflect.ValueOf(&Channel{}).Elem(), "")
+
+	expected := "podcast.yml:4:9: Items.S01E01: unknown field `Titel`, did you mean `Title`?"
+	if len(decoder.warnings) != 1 || decoder.warnings[0] != expected {
+		t.Errorf("expected warning `%s`, got %v", expected, decoder.warnings)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	vars := map[string]string{"DOMAIN": "https://example.xx", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+		err      string
+	}{
+		{"plain", "Domain: ${DOMAIN}", "Domain: https://example.xx", ""},
+		{"default", "Domain: ${MISSING:-https://a.xx}", "Domain: https://a.xx", ""},
+		{"default of empty", "Domain: ${EMPTY:-https://a.xx}", "Domain: https://a.xx", ""},
+		{"empty", "Domain: ${EMPTY}", "Domain: ", ""},
+		{"escaped", "Title: $${DOMAIN}", "Title: ${DOMAIN}", ""},
+		{"undefined", "Title: x\nDomain: ${MISSING}", "", "undefined variable `MISSING` (line 2)"},
+
+		// comments are not changed
+		{"comment line", "# ${MISSING}\nDomain: ${DOMAIN}", "# ${MISSING}\nDomain: https://example.xx", ""},
+		{"indented comment line", "Items:\n    # ${MISSING}", "Items:\n    # ${MISSING}", ""},
+		{"inline comment", "Domain: ${DOMAIN} # ${MISSING}", "Domain: https://example.xx # ${MISSING}", ""},
+		{"inline comment after apostrophe", "Title: Don't ${DOMAIN} # ${MISSING}", "Title: Don't https://example.xx # ${MISSING}", ""},
+		{"TOML comment", "Domain = \"${DOMAIN}\" # ${MISSING}", "Domain = \"https://example.xx\" # ${MISSING}", ""},
+
+		// `#` in values
+		{"hash in word", "Title: C#${DOMAIN}", "Title: C#https://example.xx", ""},
+		{"double quoted", `Title: "#1 ${DOMAIN}"`, `Title: "#1 https://example.xx"`, ""},
+		{"double quoted escape", `Title: "a\" # ${DOMAIN}"`, `Title: "a\" # https://example.xx"`, ""},
+		{"single quoted", "Title: 'a # ${DOMAIN}'", "Title: 'a # https://example.xx'", ""},
+		{"single quoted escape", "Title: 'it''s # ${DOMAIN}'", "Title: 'it''s # https://example.xx'", ""},
+		{"JSON", `{"Title": "a # ${DOMAIN}", "Domain": "${DOMAIN}"}`, `{"Title": "a # https://example.xx", "Domain": "https://example.xx"}`, ""},
+		{"flow list", "Keywords: [a, '# ${DOMAIN}']", "Keywords: [a, '# https://example.xx']", ""},
+		{"block scalar", "Description: |\n    Episode #1 ${DOMAIN}\n\n    # ${DOMAIN}\nTitle: x # ${MISSING}",
+			"Description: |\n    Episode #1 https://example.xx\n\n    # https://example.xx\nTitle: x # ${MISSING}", ""},
+		{"TOML multi-line", "Description = \"\"\"\nEpisode #1 ${DOMAIN}\n\"\"\" # ${MISSING}",
+			"Description = \"\"\"\nEpisode #1 https://example.xx\n\"\"\" # ${MISSING}", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := interpolate([]byte(tt.config), lookup)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("expected error `%s`, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(buf) != tt.expected {
+				t.Errorf("expected\n%s\ngot\n%s", tt.expected, buf)
+			}
+		})
+	}
+}
+
+func TestConfigFormatsParity(t *testing.T) {
+	configs := map[string]string{
+		"podcast.yml": testConfig + `    S01E02:
+        Title: Second
+        Description: Second episode
+        File: episodes/S01E02.mp3
+        PubDate: 2020-07-21
+        Season: 1
+        Episode: 2
+        Explicit: true
+        Duration: "1:02:03"
+`,
+		"podcast.json": `{
+    "Title": "Podcast example",
+    "Domain": "https://example.xx",
+    "Author": "Neo and Trinity",
+    "Owner": "John, john@example.xx",
+    "Description": "Long description of this podcast.",
+    "Summary": "Very short description",
+    "Image": "/podcast.png",
+    "SelfLink": "/feed.xml",
+    "Category": "TV & Film, TV Reviews",
+    "Items": {
+        "S01E01": {
+            "Title": "First",
+            "Description": "First episode",
+            "File": "episodes/S01E01.mp3",
+            "PubDate": "2020-07-14",
+            "Duration": "52:11"
+        },
+        "S01E02": {
+            "Title": "Second",
+            "Description": "Second episode",
+            "File": "episodes/S01E02.mp3",
+            "PubDate": "2020-07-21",
+            "Season": 1,
+            "Episode": 2,
+            "Explicit": true,
+            "Duration": "1:02:03"
+        }
+    }
+}`,
+		"podcast.toml": `Title = "Podcast example"
+Domain = "https://example.xx"
+Author = "Neo and Trinity"
+Owner = "John, john@example.xx"
+Description = "Long description of this podcast."
+Summary = "Very short description"
+Image = "/podcast.png"
+SelfLink = "/feed.xml"
+Category = "TV & Film, TV Reviews"
+
+[Items.S01E01]
+Title = "First"
+Description = "First episode"
+File = "episodes/S01E01.mp3"
+PubDate = 2020-07-14
+Duration = "52:11"
+
+[Items.S01E02]
+Title = "Second"
+Description = "Second episode"
+File = "episodes/S01E02.mp3"
+PubDate = 2020-07-21
+Season = 1
+Episode = 2
+Explicit = true
+Duration = "1:02:03"
+`,
+	}
+
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	build := func(configPath string) string {
+		t.Helper()
+		fsys := newTestFS("")
+		fsys["episodes/S01E02.mp3"] = &fstest.MapFile{Data: []byte("ID3 second episode")}
+		fsys[configPath] = &fstest.MapFile{Data: []byte(configs[configPath])}
+
+		p, err := New(configPath, WithFS(fsys), WithClock(func() time.Time { return now }))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.FixContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Validate(); err != nil {
+			t.Fatalf("%s: %s", configPath, err)
+		}
+
+		var buf bytes.Buffer
+		if _, err := p.Feed.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	expected := build("podcast.yml")
+	for _, configPath := range []string{"podcast.json", "podcast.toml"} {
+		if got := build(configPath); got != expected {
+			t.Errorf("%s: feed differs from YAML\n%s\nexpected\n%s", configPath, got, expected)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package podcast
 
 import (
 	"encoding/xml"
+	"fmt"
 	"time"
 )
 
@@ -24,9 +25,32 @@ func (date *Date) IsZero() bool {
 	return date == nil || date.Time.IsZero()
 }
 
+// Date formats accepted as string
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123,
+	time.RFC1123Z,
+}
+
 // UnmarshalYAML ..
 func (date *Date) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	unmarshal(&date.Time)
+	if err := unmarshal(&date.Time); err == nil {
+		return nil
+	}
+
+	// Quoted dates are not resolved as timestamp
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			date.Time = t
+			return nil
+		}
+	}
 
-	return nil
+	return fmt.Errorf("cannot parse %q as date", s)
 }
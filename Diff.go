@@ -0,0 +1,260 @@
+package podcast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Fields changing on every build, not compared
+var volatileFeedFields = []string{"lastBuildDate"}
+
+// FeedDiff - changes of channel fields and episodes between two feeds
+type FeedDiff struct {
+	Channel  []*FieldChange `json:"channel,omitempty"`
+	Added    []*DiffItem    `json:"added,omitempty"`
+	Removed  []*DiffItem    `json:"removed,omitempty"`
+	Modified []*DiffItem    `json:"modified,omitempty"`
+}
+
+// FieldChange - changed XML element or attribute (`itunes:owner/itunes:email`, `enclosure@url`).
+// Empty `Old` for added field, empty `New` for removed
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// DiffItem - added, removed or modified episode
+type DiffItem struct {
+	GUID    string         `json:"guid"`
+	Title   string         `json:"title"`
+	Changes []*FieldChange `json:"changes,omitempty"`
+}
+
+// DiffFeeds - changes from `old` to `new` feed. Build dates are ignored
+func DiffFeeds(old, new *XMLRoot) (*FeedDiff, error) {
+	var oldXML, newXML bytes.Buffer
+	if _, err := old.WriteTo(&oldXML); err != nil {
+		return nil, err
+	}
+	if _, err := new.WriteTo(&newXML); err != nil {
+		return nil, err
+	}
+	return DiffFeedXML(&oldXML, &newXML)
+}
+
+// DiffFeedXML - changes from `old` to `new` RSS feed. Build dates are ignored
+func DiffFeedXML(old, new io.Reader) (*FeedDiff, error) {
+	oldChannel, err := parseFeedChannel(old)
+	if err != nil {
+		return nil, fmt.Errorf("old feed: %s", err)
+	}
+	newChannel, err := parseFeedChannel(new)
+	if err != nil {
+		return nil, fmt.Errorf("new feed: %s", err)
+	}
+
+	diff := &FeedDiff{
+		Channel: diffFields(flattenXMLNode(oldChannel, "item"), flattenXMLNode(newChannel, "item")),
+	}
+
+	oldItems := oldChannel.children("item")
+	newItems := newChannel.children("item")
+
+	// match episodes by GUID, then by title
+	oldByGUID := map[string][]*xmlNode{}
+	oldByTitle := map[string][]*xmlNode{}
+	for _, oldItem := range oldItems {
+		if guid := oldItem.childText("guid"); guid != "" {
+			oldByGUID[guid] = append(oldByGUID[guid], oldItem)
+		}
+		if title := oldItem.childText("title"); title != "" {
+			oldByTitle[title] = append(oldByTitle[title], oldItem)
+		}
+	}
+
+	matched := map[*xmlNode]*xmlNode{}
+	used := map[*xmlNode]bool{}
+	for _, match := range []struct {
+		index map[string][]*xmlNode
+		name  string
+	}{{oldByGUID, "guid"}, {oldByTitle, "title"}} {
+		for _, newItem := range newItems {
+			if matched[newItem] != nil {
+				continue
+			}
+			for _, oldItem := range match.index[newItem.childText(match.name)] {
+				if !used[oldItem] {
+					matched[newItem] = oldItem
+					used[oldItem] = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, newItem := range newItems {
+		oldItem := matched[newItem]
+		if oldItem == nil {
+			diff.Added = append(diff.Added, newDiffItem(newItem))
+			continue
+		}
+
+		changes := diffFields(flattenXMLNode(oldItem), flattenXMLNode(newItem))
+		if len(changes) > 0 {
+			item := newDiffItem(newItem)
+			item.Changes = changes
+			diff.Modified = append(diff.Modified, item)
+		}
+	}
+
+	for _, oldItem := range oldItems {
+		if !used[oldItem] {
+			diff.Removed = append(diff.Removed, newDiffItem(oldItem))
+		}
+	}
+
+	return diff, nil
+}
+
+// Channel element of RSS feed
+func parseFeedChannel(r io.Reader) (*xmlNode, error) {
+	root, err := parseXMLNode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := root.child("channel")
+	if root.Name != "rss" || channel == nil {
+		return nil, fmt.Errorf("not RSS feed")
+	}
+	return channel, nil
+}
+
+func newDiffItem(node *xmlNode) *DiffItem {
+	return &DiffItem{
+		GUID:  node.childText("guid"),
+		Title: node.childText("title"),
+	}
+}
+
+// Values of child elements and attributes by path. Elements named in `skip` are left out
+func flattenXMLNode(node *xmlNode, skip ...string) map[string]string {
+	fields := map[string]string{}
+
+	var walk func(node *xmlNode, prefix string)
+	walk = func(node *xmlNode, prefix string) {
+		seen := map[string]int{}
+		for _, child := range node.Children {
+			if prefix == "" && (inSlice(child.Name, skip) || inSlice(child.Name, volatileFeedFields)) {
+				continue
+			}
+
+			// repeated elements get index: `itunes:category[1]`
+			name := prefix + child.Name
+			if n := seen[child.Name]; n > 0 {
+				name = fmt.Sprintf("%s[%d]", name, n)
+			}
+			seen[child.Name]++
+
+			if child.Text != "" || len(child.Children) == 0 {
+				fields[name] = child.Text
+			}
+			for attr, value := range child.Attrs {
+				fields[name+"@"+attr] = value
+			}
+			walk(child, name+"/")
+		}
+	}
+	walk(node, "")
+
+	return fields
+}
+
+// Changed fields sorted by name
+func diffFields(old, new map[string]string) []*FieldChange {
+	var changes []*FieldChange
+	for field, value := range new {
+		if oldValue, ok := old[field]; !ok && value != "" || ok && oldValue != value {
+			changes = append(changes, &FieldChange{Field: field, Old: oldValue, New: value})
+		}
+	}
+	for field, value := range old {
+		if _, ok := new[field]; !ok && value != "" {
+			changes = append(changes, &FieldChange{Field: field, Old: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// IsEmpty - feeds are the same
+func (diff *FeedDiff) IsEmpty() bool {
+	return len(diff.Channel) == 0 && len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Modified) == 0
+}
+
+// WriteJSON - diff as JSON
+func (diff *FeedDiff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(diff)
+}
+
+// WriteText - diff for humans
+func (diff *FeedDiff) WriteText(w io.Writer) error {
+	_, err := io.WriteString(w, diff.String())
+	return err
+}
+
+func (diff *FeedDiff) String() string {
+	if diff.IsEmpty() {
+		return "No changes\n"
+	}
+
+	var b strings.Builder
+	if len(diff.Channel) > 0 {
+		b.WriteString("Channel:\n")
+		writeFieldChanges(&b, diff.Channel, "  ")
+	}
+	if len(diff.Added) > 0 {
+		b.WriteString("Added episodes:\n")
+		for _, item := range diff.Added {
+			fmt.Fprintf(&b, "  + %s (%s)\n", item.Title, item.GUID)
+		}
+	}
+	if len(diff.Removed) > 0 {
+		b.WriteString("Removed episodes:\n")
+		for _, item := range diff.Removed {
+			fmt.Fprintf(&b, "  - %s (%s)\n", item.Title, item.GUID)
+		}
+	}
+	if len(diff.Modified) > 0 {
+		b.WriteString("Modified episodes:\n")
+		for _, item := range diff.Modified {
+			fmt.Fprintf(&b, "  ~ %s (%s)\n", item.Title, item.GUID)
+			writeFieldChanges(&b, item.Changes, "      ")
+		}
+	}
+
+	return b.String()
+}
+
+func writeFieldChanges(b *strings.Builder, changes []*FieldChange, indent string) {
+	for _, change := range changes {
+		switch {
+		case change.Old == "":
+			fmt.Fprintf(b, "%s+ %s: %q\n", indent, change.Field, change.New)
+		case change.New == "":
+			fmt.Fprintf(b, "%s- %s: %q\n", indent, change.Field, change.Old)
+		default:
+			fmt.Fprintf(b, "%s~ %s: %q ==> %q\n", indent, change.Field, change.Old, change.New)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package podcast
+
+import (
+	"strings"
+	"testing"
+)
+
+const diffOldFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+  <lastBuildDate>Mon, 01 Jun 2020 10:00:00 UTC</lastBuildDate>
+  <channel>
+    <title>Podcast</title>
+    <lastBuildDate>Mon, 01 Jun 2020 10:00:00 UTC</lastBuildDate>
+    <itunes:category text="TV &amp; Film"></itunes:category>
+    <item>
+      <title>First</title>
+      <guid>g1</guid>
+      <enclosure url="https://example.xx/1.mp3" length="1" type="audio/mpeg"></enclosure>
+    </item>
+    <item>
+      <title>Second</title>
+      <guid>g2</guid>
+      <enclosure url="https://example.xx/2.mp3" length="1" type="audio/mpeg"></enclosure>
+    </item>
+    <item>
+      <title>Third</title>
+      <guid>g3</guid>
+    </item>
+  </channel>
+</rss>`
+
+func TestDiffFeedXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		new      string
+		expected string
+	}{
+		{
+			name: "build date ignored",
+			new: strings.NewReplacer(
+				"Mon, 01 Jun 2020 10:00:00 UTC", "Tue, 02 Jun 2020 11:00:00 UTC",
+			).Replace(diffOldFeed),
+			expected: "No changes\n",
+		},
+		{
+			name: "channel field",
+			new: strings.NewReplacer(
+				"<title>Podcast</title>", "<title>New podcast</title>",
+				`text="TV &amp; Film"`, `text="Comedy"`,
+			).Replace(diffOldFeed),
+			expected: "Channel:\n" +
+				"  ~ itunes:category@text: \"TV & Film\" ==> \"Comedy\"\n" +
+				"  ~ title: \"Podcast\" ==> \"New podcast\"\n",
+		},
+		{
+			name: "added, removed and modified episodes",
+			new: strings.NewReplacer(
+				"<title>Third</title>\n      <guid>g3</guid>", "<title>Fourth</title>\n      <guid>g4</guid>",
+				"https://example.xx/2.mp3", "https://example.xx/2b.mp3",
+			).Replace(diffOldFeed),
+			expected: "Added episodes:\n" +
+				"  + Fourth (g4)\n" +
+				"Removed episodes:\n" +
+				"  - Third (g3)\n" +
+				"Modified episodes:\n" +
+				"  ~ Second (g2)\n" +
+				"      ~ enclosure@url: \"https://example.xx/2.mp3\" ==> \"https://example.xx/2b.mp3\"\n",
+		},
+		{
+			name: "episode matched by title when GUID changed",
+			new:  strings.Replace(diffOldFeed, "<guid>g1</guid>", "<guid>g1b</guid>", 1),
+			expected: "Modified episodes:\n" +
+				"  ~ First (g1b)\n" +
+				"      ~ guid: \"g1\" ==> \"g1b\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := DiffFeedXML(strings.NewReader(diffOldFeed), strings.NewReader(tt.new))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := diff.String(); got != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffFeedXMLNotRSS(t *testing.T) {
+	if _, err := DiffFeedXML(strings.NewReader(diffOldFeed), strings.NewReader("<html></html>")); err == nil {
+		t.Error("expected error for not RSS feed")
+	}
+}
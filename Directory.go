@@ -0,0 +1,274 @@
+package podcast
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dhowden/tag"
+)
+
+// Extensions of audio files picked up by `FromDirectory`
+var audioFileExtensions = []string{".mp3", ".m4a", ".aac", ".ogg", ".oga", ".opus", ".flac", ".wav"}
+
+var (
+	reFileKey  = regexp.MustCompile(`(?i)S(\d{1,2})E(\d{1,3})`)
+	reFileDate = regexp.MustCompile(`(\d{4})-(\d{2})-(\d{2})`)
+)
+
+// FromDirectory - podcast from all audio files found in `dir`.
+// Optional `podcast.yml` (or `.yaml`, `.json`, `.toml`) in `dir` is loaded for channel info and items,
+// missing channel values are taken from `defaults`.
+// Items are created for audio files not mentioned in `podcast.yml`:
+//   - key, season and episode from file name `S01E02`
+//   - title from audio tags or file name
+//   - publish date from `2020-07-14` in file name or file modification time
+//   - duration detected in `Item.Fix`
+func FromDirectory(dir string, defaults *Channel, options ...Option) (*Podcast, error) {
+	podcast := newPodcast(filepath.Join(dir, "podcast.yml"), options...)
+
+	fsys := podcast.fsys
+	dir = fsPath(fsys, dir)
+
+	for _, name := range []string{"podcast.yml", "podcast.yaml", "podcast.json", "podcast.toml"} {
+		fpath := filepath.Join(dir, name)
+		if _, err := statFile(fsys, fpath); err != nil {
+			continue
+		}
+
+		podcast.configFilepath = fpath
+		if err := podcast.Load(); err != nil {
+			return podcast, err
+		}
+		break
+	}
+
+	channel := podcast.Feed.Channel
+	if defaults != nil {
+		mergeChannel(channel, defaults)
+	}
+
+	// already configured files and keys
+	keys := map[string]bool{}
+	files := map[string]bool{}
+	for _, item := range channel.Items {
+		keys[item.Key] = true
+		files[fsPath(fsys, filepath.Clean(item.File))] = true
+		files[fsPath(fsys, filepath.Join(dir, item.File))] = true
+	}
+
+	var items ItemList
+	err := fs.WalkDir(fsys, dir, func(fpath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// skip hidden directories and files
+		if fpath != dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() || files[fsPath(fsys, filepath.Clean(fpath))] {
+			return nil
+		}
+
+		if !inSlice(strings.ToLower(filepath.Ext(fpath)), audioFileExtensions) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		item, err := itemFromFile(fsys, dir, fpath, info)
+		if err != nil {
+			return fmt.Errorf("%s: %s", fpath, err)
+		}
+
+		if keys[item.Key] {
+			return fmt.Errorf("%s: duplicate item key `%s`", fpath, item.Key)
+		}
+		keys[item.Key] = true
+
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return podcast, err
+	}
+
+	numberItems(channel.Items, items)
+
+	channel.Items = append(channel.Items, items...)
+	sort.Sort(channel.Items)
+
+	return podcast, nil
+}
+
+// Item from audio file. Path relative to `dir` is used as `FileURL`
+func itemFromFile(fsys fs.FS, dir, fpath string, info fs.FileInfo) (*Item, error) {
+	name := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
+
+	relPath, err := filepath.Rel(dir, fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &Item{
+		Key:     name,
+		File:    fpath,
+		FileURL: filepath.ToSlash(relPath),
+	}
+
+	// S01E02
+	if m := reFileKey.FindStringSubmatch(name); m != nil {
+		item.Season, _ = strconv.Atoi(m[1])
+		item.Episode, _ = strconv.Atoi(m[2])
+		item.Key = fmt.Sprintf("S%02dE%02d", item.Season, item.Episode)
+	}
+
+	// 2020-07-14
+	if m := reFileDate.FindString(name); m != "" {
+		if t, err := time.Parse("2006-01-02", m); err == nil {
+			item.PubDate = &Date{t}
+		}
+	}
+	if item.PubDate.IsZero() {
+		item.PubDate = &Date{info.ModTime()}
+	}
+
+	// Title and description from audio tags
+	if f, err := openFile(fsys, fpath); err == nil {
+		if rs, ok := f.(io.ReadSeeker); ok {
+			if meta, err := tag.ReadFrom(rs); err == nil {
+				item.Title = strings.TrimSpace(meta.Title())
+				if comment := strings.TrimSpace(meta.Comment()); comment != "" {
+					item.Description = &CDATA{Text: comment}
+				}
+			}
+		}
+		f.Close()
+	}
+
+	// Title from file name
+	if item.Title == "" {
+		title := reFileDate.ReplaceAllString(reFileKey.ReplaceAllString(name, ""), "")
+		title = strings.Join(strings.FieldsFunc(title, func(r rune) bool {
+			return r == '_' || r == '-' || r == '.' || r == ' '
+		}), " ")
+		if title == "" {
+			title = name
+		}
+		item.Title = title
+	}
+
+	if item.Description.IsEmpty() {
+		item.Description = &CDATA{Text: item.Title}
+	}
+
+	return item, nil
+}
+
+// Assign season and episode numbers to items without them.
+// Numbered as episodes of the first season by publish date after the last existing one.
+func numberItems(existing, items ItemList) {
+	last := 0
+	for _, list := range []ItemList{existing, items} {
+		for _, item := range list {
+			if item.Season == 1 && item.Episode > last {
+				last = item.Episode
+			}
+		}
+	}
+
+	var unnumbered ItemList
+	for _, item := range items {
+		if item.Season == 0 && item.Episode == 0 {
+			unnumbered = append(unnumbered, item)
+		}
+	}
+
+	sort.SliceStable(unnumbered, func(i, j int) bool {
+		return unnumbered[i].PubDate.Before(unnumbered[j].PubDate.Time)
+	})
+
+	for _, item := range unnumbered {
+		last++
+		item.Season = 1
+		item.Episode = last
+	}
+}
+
+// Copy values from `src` into empty fields of `dst`. Items are not copied.
+func mergeChannel(dst, src *Channel) {
+	dv := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(src).Elem()
+
+	for i := 0; i < dv.NumField(); i++ {
+		field := dv.Type().Field(i)
+		if field.PkgPath != "" || field.Name == "Items" {
+			continue
+		}
+
+		if dv.Field(i).IsZero() {
+			dv.Field(i).Set(deepCopy(sv.Field(i)))
+		}
+	}
+}
+
+// Copy of value with own pointers, maps and slices, so fixing `dst` doesn't change `src`
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	}
+
+	return v
+}
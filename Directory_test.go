@@ -0,0 +1,35 @@
+package podcast
+
+import "testing"
+
+func TestMergeChannelCopies(t *testing.T) {
+	defaults := &Channel{
+		Title:    "Defaults",
+		Image:    &Image{URL: "/podcast.png"},
+		SelfLink: &AttrHref{Href: "/feed.xml"},
+		Seasons:  map[int]*Season{1: {Author: "Neo"}},
+		Feeds:    map[string]*Feed{"es": {Language: "es"}},
+
+		EnclosurePrefixes: []string{"https://op3.dev/e/"},
+	}
+
+	channel := &Channel{Title: "Channel"}
+	mergeChannel(channel, defaults)
+
+	if channel.Title != "Channel" || channel.Image.URL != "/podcast.png" || channel.Seasons[1].Author != "Neo" {
+		t.Fatalf("values not merged: %+v", channel)
+	}
+
+	channel.Image.Title = "Changed"
+	channel.SelfLink.Href = "https://example.xx/feed.xml"
+	channel.Seasons[1].Author = "Trinity"
+	channel.Seasons[2] = &Season{}
+	channel.Feeds["es"].Language = "en"
+	channel.EnclosurePrefixes[0] = "https://changed/"
+
+	if defaults.Image.Title != "" || defaults.SelfLink.Href != "/feed.xml" ||
+		defaults.Seasons[1].Author != "Neo" || len(defaults.Seasons) != 1 ||
+		defaults.Feeds["es"].Language != "es" || defaults.EnclosurePrefixes[0] != "https://op3.dev/e/" {
+		t.Errorf("defaults changed: %+v", defaults)
+	}
+}
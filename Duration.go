@@ -1,6 +1,7 @@
 package podcast
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -13,13 +14,11 @@ type Duration int
 // UnmarshalYAML ..
 func (dur *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var s string
-	unmarshal(&s)
-
-	if err := dur.Set(s); err != nil {
+	if err := unmarshal(&s); err != nil {
 		return err
 	}
 
-	return nil
+	return dur.Set(s)
 }
 
 // Set duration from string
@@ -30,6 +29,7 @@ func (dur *Duration) Set(s string) error {
 		*dur = Duration(totalSeconds)
 		return nil
 	}
+	value := s
 
 	// If not try to convert from common duration formats
 
@@ -45,7 +45,7 @@ func (dur *Duration) Set(s string) error {
 	s += "s"                            // 00h52m11s
 	d, err := time.ParseDuration(s)
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot parse %q", value)
 	}
 
 	*dur = Duration(d.Seconds())
@@ -0,0 +1,241 @@
+package podcast
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DownloadEvent - episode file served by `EnclosureHandler`
+type DownloadEvent struct {
+	Time      time.Time `json:"time"`
+	Key       string    `json:"key"`
+	GUID      string    `json:"guid"`
+	URL       string    `json:"url"`
+	Status    int       `json:"status"`
+	Bytes     int64     `json:"bytes"`           // bytes of file sent
+	Range     string    `json:"range,omitempty"` // `Range` header of request
+	UserAgent string    `json:"userAgent"`
+	IP        string    `json:"ip"`
+
+	// Subscriber of private feed
+	Subscriber string `json:"subscriber,omitempty"`
+
+	// Client detected from user agent
+	App      string `json:"app,omitempty"`
+	Platform string `json:"platform,omitempty"`
+	Bot      bool   `json:"bot,omitempty"`
+}
+
+// DownloadSink - receives download events. Called for every GET request of an episode file
+// so it should return quickly.
+type DownloadSink interface {
+	Download(event DownloadEvent)
+}
+
+// DownloadSinkFunc - function as `DownloadSink`
+type DownloadSinkFunc func(event DownloadEvent)
+
+// Download ..
+func (f DownloadSinkFunc) Download(event DownloadEvent) {
+	f(event)
+}
+
+// JSONDownloadSink - writes download events to `w` as JSON lines
+func JSONDownloadSink(w io.Writer) DownloadSink {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+
+	return DownloadSinkFunc(func(event DownloadEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := enc.Encode(event); err != nil {
+			log.Printf("[podcast] Download event not written. %s", err)
+		}
+	})
+}
+
+// EnclosureHandler - serves episode files (`Item.File`) at URL paths of their enclosures.
+// Supports `Range`, `If-Range` and `HEAD` requests.
+//
+//	http.Handle("/episodes/", podcast.NewEnclosureHandler(p))
+type EnclosureHandler struct {
+	// Sink - receives download event of every GET request. Optional
+	Sink DownloadSink
+
+	// TrustProxy - take client IP from `X-Forwarded-For` header
+	TrustProxy bool
+
+	// UserAgents - detects apps and bots of download events. Embedded rules by default
+	UserAgents *UserAgentClassifier
+
+	// Signer - serve files only to subscribers with valid token. Optional
+	Signer *FeedSigner
+
+	channel *Channel
+	items   map[string]*Item // by URL path
+}
+
+// NewEnclosureHandler - handler for episode files of `podcast`
+func NewEnclosureHandler(podcast *Podcast) *EnclosureHandler {
+	channel := podcast.Feed.Channel
+
+	h := &EnclosureHandler{
+		channel:    channel,
+		items:      map[string]*Item{},
+		UserAgents: DefaultUserAgentClassifier(),
+	}
+	for _, item := range channel.Items {
+		if p := enclosurePath(item); p != "" {
+			h.items[p] = item
+		}
+	}
+
+	return h
+}
+
+// ServeHTTP - serve episode file matching request path
+func (h *EnclosureHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// token first: episode paths of private feed are not revealed by 404
+	var subscriber string
+	if h.Signer != nil {
+		var status int
+		if subscriber, status = h.Signer.verifyRequest(r); status != 0 {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+	}
+
+	item := h.items[cleanURLPath(r.URL.Path)]
+	if item == nil || item.File == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	fsys := h.channel.fs()
+	f, err := openFile(fsys, item.File)
+	if err != nil {
+		log.Printf("[podcast] Item[%s] file not served. %s", item.Key, err)
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		log.Printf("[podcast] Item[%s] file `%s` can't seek", item.Key, item.File)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	mimeType := item.FileMimeType
+	if mimeType == "" {
+		if mime, err := detectMimeType(fsys, item.File); err == nil {
+			mimeType = mime.String()
+		}
+	}
+	if mimeType != "" {
+		w.Header().Set("Content-Type", mimeType)
+	}
+
+	cw := &countResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	http.ServeContent(cw, r, info.Name(), info.ModTime(), content)
+
+	if h.Sink == nil || r.Method != http.MethodGet {
+		return
+	}
+
+	event := DownloadEvent{
+		Time:       time.Now(),
+		Key:        item.Key,
+		URL:        r.URL.Path,
+		Status:     cw.status,
+		Bytes:      cw.n,
+		Range:      r.Header.Get("Range"),
+		UserAgent:  r.UserAgent(),
+		IP:         clientIP(r, h.TrustProxy),
+		Subscriber: subscriber,
+	}
+	if !item.GUID.IsEmpty() {
+		event.GUID = item.GUID.Text
+	}
+	if h.UserAgents != nil {
+		client := h.UserAgents.Classify(event.UserAgent)
+		event.App, event.Platform, event.Bot = client.App, client.Platform, client.Bot
+	}
+	h.Sink.Download(event)
+}
+
+// URL path of item enclosure from raw `FileURL` (or `File`) as in `Item.Fix`
+func enclosurePath(item *Item) string {
+	s := item.FileURL
+	if s == "" {
+		s = item.File
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	return cleanURLPath(u.Path)
+}
+
+// `./episodes/S01E01.mp3` ==> `/episodes/S01E01.mp3`
+func cleanURLPath(p string) string {
+	p = strings.Trim(p, "./")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+// IP of client. First address of `X-Forwarded-For` if `trustProxy`
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			return strings.TrimSpace(strings.Split(fwd, ",")[0])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Counts bytes of response body and keeps status code
+type countResponseWriter struct {
+	http.ResponseWriter
+	status int
+	n      int64
+}
+
+func (w *countResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *countResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,78 @@
+package podcast
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// osFS - local file system with paths as given (relative to working directory or absolute)
+type osFS struct{}
+
+// Open ..
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// Stat ..
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// ReadFile ..
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// ReadDir ..
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// Glob ..
+func (osFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// Is local file system where external tools can access files by path
+func isOSFS(fsys fs.FS) bool {
+	_, ok := fsys.(osFS)
+	return ok
+}
+
+// Path of `name` in file system.
+// For other than local file system: `./episodes/S01E01.mp3` ==> `episodes/S01E01.mp3`
+func fsPath(fsys fs.FS, name string) string {
+	if isOSFS(fsys) {
+		return name
+	}
+
+	name = path.Clean(filepath.ToSlash(name))
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		name = "."
+	}
+	return name
+}
+
+// Stat file in file system
+func statFile(fsys fs.FS, name string) (fs.FileInfo, error) {
+	return fs.Stat(fsys, fsPath(fsys, name))
+}
+
+// Read file from file system
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	return fs.ReadFile(fsys, fsPath(fsys, name))
+}
+
+// Open file from file system
+func openFile(fsys fs.FS, name string) (fs.File, error) {
+	return fsys.Open(fsPath(fsys, name))
+}
+
+// Files matching pattern in file system
+func globFiles(fsys fs.FS, pattern string) ([]string, error) {
+	return fs.Glob(fsys, fsPath(fsys, pattern))
+}
@@ -0,0 +1,157 @@
+package podcast
+
+import (
+	"path"
+	"strings"
+)
+
+// Feed - additional feed derived from the same podcast config.
+// Items are filtered and some channel fields can be overwritten.
+//
+//	Feeds:
+//	    season-3:
+//	        Title: Podcast example - Season 3
+//	        SelfLink: /season-3.xml
+//	        Seasons: [3]
+//	    spanish:
+//	        Title: Podcast example (ES)
+//	        Language: es
+type Feed struct {
+	Key string `yaml:"-"`
+
+	// Output file name. By default taken from `SelfLink` or `<key>.xml`
+	File string `yaml:"File"`
+
+	// Filters. Empty filter matches all items
+	Seasons      []int    `yaml:"Seasons"`
+	Tags         []string `yaml:"Tags"`
+	Language     string   `yaml:"Language"`
+	EpisodeTypes []string `yaml:"EpisodeTypes"`
+
+	// Channel overrides
+	Title      string    `yaml:"Title"`
+	SelfLink   *AttrHref `yaml:"SelfLink"`
+	NewFeedURL string    `yaml:"NewFeedURL"`
+	Image      *Image    `yaml:"Image"`
+}
+
+// Match - is item included in this feed
+func (feed *Feed) Match(item *Item) bool {
+	if len(feed.Seasons) > 0 && !inSliceInt(item.Season, feed.Seasons) {
+		return false
+	}
+
+	if feed.Language != "" && !strings.EqualFold(feed.Language, item.Language) {
+		return false
+	}
+
+	if len(feed.EpisodeTypes) > 0 && !inSlice(item.EpisodeType, feed.EpisodeTypes) {
+		return false
+	}
+
+	if len(feed.Tags) > 0 {
+		found := false
+		for _, tag := range item.Tags {
+			if inSlice(tag, feed.Tags) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FileName - where to save this feed
+func (feed *Feed) FileName() string {
+	if feed.File != "" {
+		return feed.File
+	}
+	return feedFileName(feed.SelfLink, feed.Key+".xml")
+}
+
+// Derive channel for this feed from already fixed `channel`.
+// Items are shared with the main channel.
+func (feed *Feed) Derive(channel *Channel) *Channel {
+	derived := *channel
+	derived.Feeds = nil
+
+	// new URL of main feed is not new URL of this feed
+	derived.NewFeedURL = feed.NewFeedURL
+	if derived.NewFeedURL != "" && !isValidURL(derived.NewFeedURL) {
+		derived.NewFeedURL = pathToURL(derived.Domain, derived.NewFeedURL)
+	}
+
+	if feed.Title != "" {
+		derived.Title = feed.Title
+		derived.ItunesTitle = feed.Title
+	}
+
+	if feed.Language != "" {
+		derived.Language = strings.ToLower(feed.Language)
+	}
+
+	// own URL and `podcast:guid`: feed file next to main feed by default
+	var href string
+	if !feed.SelfLink.IsEmpty() {
+		href = feed.SelfLink.Href
+	} else if derived.Domain != "" {
+		fname := strings.TrimPrefix(path.Clean("/"+feed.FileName()), "/")
+		href = "/" + fname
+		if !channel.SelfLink.IsEmpty() {
+			href = strings.TrimSuffix(channel.SelfLink.Href, path.Base(channel.SelfLink.Href)) + fname
+		}
+	}
+	if href != "" {
+		derived.SelfLink = &AttrHref{
+			Href: href,
+			Rel:  "self",
+			Type: "application/rss+xml",
+		}
+		if !isValidURL(derived.SelfLink.Href) {
+			derived.SelfLink.Href = pathToURL(derived.Domain, derived.SelfLink.Href)
+		}
+		derived.PodcastGUID = PodcastGUID(derived.SelfLink.Href)
+	}
+
+	image := derived.Image
+	if feed.Image != nil && feed.Image.URL != "" {
+		image = &Image{URL: feed.Image.URL}
+		if !isValidURL(image.URL) {
+			image.URL = pathToURL(derived.Domain, image.URL)
+		}
+		derived.ItunesImage = &AttrHref{Href: image.URL}
+	}
+	if image != nil {
+		derived.Image = &Image{
+			URL:   image.URL,
+			Title: derived.Title,
+			Link:  derived.Link,
+		}
+	}
+
+	derived.Items = nil
+	for _, item := range channel.Items {
+		if feed.Match(item) {
+			derived.Items = append(derived.Items, item)
+		}
+	}
+
+	return &derived
+}
+
+// file name from the last part of `SelfLink` or `fallback`
+func feedFileName(selfLink *AttrHref, fallback string) string {
+	if selfLink.IsEmpty() {
+		return fallback
+	}
+
+	name := path.Base(selfLink.Href)
+	if name == "" || name == "." || name == "/" || !strings.Contains(name, ".") {
+		return fallback
+	}
+	return name
+}
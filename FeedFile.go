@@ -0,0 +1,57 @@
+package podcast
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"time"
+)
+
+// Build dates change on every build and are ignored when comparing feeds
+var reLastBuildDate = regexp.MustCompile(`<lastBuildDate>([^<]*)</lastBuildDate>`)
+
+// Compare feed files ignoring build dates.
+// Returns build date of `oldPath` if files are the same.
+func sameFeedFiles(oldPath, newPath string) (bool, *Date) {
+	oldFile, err := os.Open(oldPath)
+	if err != nil {
+		return false, nil
+	}
+	defer oldFile.Close()
+
+	newFile, err := os.Open(newPath)
+	if err != nil {
+		return false, nil
+	}
+	defer newFile.Close()
+
+	var buildDate *Date
+	oldReader := bufio.NewReader(oldFile)
+	newReader := bufio.NewReader(newFile)
+
+	for {
+		oldLine, oldErr := oldReader.ReadBytes('\n')
+		newLine, newErr := newReader.ReadBytes('\n')
+
+		if buildDate == nil {
+			if m := reLastBuildDate.FindSubmatch(oldLine); m != nil {
+				if t, err := time.Parse(time.RFC1123, string(m[1])); err == nil {
+					buildDate = &Date{t}
+				}
+			}
+		}
+
+		if !bytes.Equal(reLastBuildDate.ReplaceAll(oldLine, nil), reLastBuildDate.ReplaceAll(newLine, nil)) {
+			return false, nil
+		}
+
+		if oldErr == io.EOF && newErr == io.EOF {
+			return true, buildDate
+		}
+		if oldErr != nil || newErr != nil {
+			return false, nil
+		}
+	}
+}
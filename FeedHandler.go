@@ -0,0 +1,436 @@
+package podcast
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FeedContentType - content type of served feed
+const FeedContentType = "application/rss+xml; charset=utf-8"
+
+// Failed build with unchanged config is tried again after this time
+const feedRetryInterval = time.Minute
+
+// Config and episode files are checked for changes at most this often
+const feedCheckInterval = time.Second
+
+// Subscribers reported by feed aggregators in user agent (`Feedly/1.0 (..; 42 subscribers; ..)`)
+var reUserAgentSubscribers = regexp.MustCompile(`(?i)(\d+) (?:subscribers|readers)`)
+
+// FeedHandler - serves feed built from config file.
+// Feed is built again only when config or episode files changed.
+// If `NewFeedURL` is set all requests are redirected there with 301.
+//
+//	http.Handle("/feed.xml", podcast.NewFeedHandler("podcast.yml"))
+type FeedHandler struct {
+	// Gzip - compress feed for clients accepting it
+	Gzip bool
+
+	// UserAgents - detects apps of feed requests for `Subscribers`. Embedded rules by default
+	UserAgents *UserAgentClassifier
+
+	// TrustProxy - take client IP from `X-Forwarded-For` header
+	TrustProxy bool
+
+	// Signer - serve private feed only to subscribers with valid token. Optional
+	Signer *FeedSigner
+
+	// OldPaths - previous paths of feed redirected to `SelfLink` with 301.
+	// Register handler on these paths too.
+	OldPaths []string
+
+	configPath string
+	options    []Option
+
+	mu       sync.Mutex // guards `feed`, `checked` and `building`
+	feed     *servedFeed
+	checked  time.Time // config files last checked for changes
+	building bool
+
+	buildMu sync.Mutex // one build at a time, guards fields below
+	podcast *Podcast   // the last built
+	version string     // of config files used by `podcast`
+	failure *feedFailure
+
+	subscribersMu sync.Mutex
+	reported      map[string]int        // by aggregator
+	clients       map[string]feedClient // by IP and user agent
+	pruned        time.Time             // clients not seen for 24 hours removed
+}
+
+// App requesting feed
+type feedClient struct {
+	app      string
+	lastSeen time.Time
+}
+
+// The last failed build
+type feedFailure struct {
+	podcast *Podcast
+	version string
+	err     error
+	time    time.Time
+}
+
+// Generated feed ready to be served
+type servedFeed struct {
+	root     *XMLRoot
+	body     []byte
+	gzipBody []byte
+	etag     string
+	modTime  time.Time
+}
+
+// NewFeedHandler - handler serving feed of config file at `configPath`.
+// `options` are given to `New` on every build.
+func NewFeedHandler(configPath string, options ...Option) *FeedHandler {
+	return &FeedHandler{
+		UserAgents: DefaultUserAgentClassifier(),
+		configPath: configPath,
+		options:    options,
+		reported:   map[string]int{},
+		clients:    map[string]feedClient{},
+	}
+}
+
+// ServeHTTP - serve feed with `ETag` and `Last-Modified`, answer conditional requests with 304
+func (h *FeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// token first: feed is not built and new location not revealed for invalid tokens
+	var token string
+	if h.Signer != nil {
+		if _, status := h.Signer.verifyRequest(r); status != 0 {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		token = r.URL.Query().Get(h.Signer.param())
+	}
+
+	feed, err := h.load()
+	if err != nil {
+		log.Printf("[podcast] Feed `%s` not built. %s", h.configPath, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// moved feed, token is kept in query
+	if target := h.redirectURL(feed.root.Channel, r); target != "" {
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		h.countSubscriber(r)
+	}
+
+	// feed with links signed for subscriber
+	if h.Signer != nil {
+		if feed, err = newServedFeed(feed.root.PrivateFeed(h.Signer, token), h.Gzip); err != nil {
+			log.Printf("[podcast] Private feed `%s` not built. %s", h.configPath, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Robots-Tag", "noindex")
+	}
+
+	body, etag := feed.body, feed.etag
+	if h.Gzip {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if acceptsGzip(r) {
+			body, etag = feed.gzipBody, strings.TrimSuffix(etag, `"`)+`-gzip"`
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+	}
+
+	w.Header().Set("Content-Type", FeedContentType)
+	w.Header().Set("ETag", etag)
+	http.ServeContent(w, r, "", feed.modTime, bytes.NewReader(body))
+}
+
+// Where request of moved feed is redirected. Empty if feed is served.
+// Query (subscriber token) is kept
+func (h *FeedHandler) redirectURL(channel *Channel, r *http.Request) string {
+	var target string
+	switch {
+	case channel.NewFeedURL != "":
+		target = channel.NewFeedURL
+	case !channel.SelfLink.IsEmpty() && h.isOldPath(r.URL.Path):
+		target = channel.SelfLink.Href
+	default:
+		return ""
+	}
+
+	// handler may serve the new location too
+	if sameFeedURL(target, r.Host+r.URL.Path) {
+		return ""
+	}
+
+	if r.URL.RawQuery != "" {
+		if strings.Contains(target, "?") {
+			target += "&" + r.URL.RawQuery
+		} else {
+			target += "?" + r.URL.RawQuery
+		}
+	}
+	return target
+}
+
+// Request path is one of `OldPaths`
+func (h *FeedHandler) isOldPath(p string) bool {
+	p = cleanURLPath(p)
+	for _, old := range h.OldPaths {
+		if cleanURLPath(old) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Feed built from current config. Built again if config changed since last build.
+// Config files are checked at most once per `feedCheckInterval`.
+// While feed is built again previous feed is served to other requests.
+// If build fails previous feed is kept
+func (h *FeedHandler) load() (*servedFeed, error) {
+	h.mu.Lock()
+	if feed := h.feed; feed != nil && (h.building || time.Since(h.checked) < feedCheckInterval) {
+		h.mu.Unlock()
+		return feed, nil
+	}
+	h.building = true
+	h.mu.Unlock()
+
+	h.buildMu.Lock()
+	defer h.buildMu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		h.building = false
+		h.checked = time.Now()
+		h.mu.Unlock()
+	}()
+
+	// checked by request waiting for the same build
+	h.mu.Lock()
+	fresh := h.feed != nil && time.Since(h.checked) < feedCheckInterval
+	h.mu.Unlock()
+	if fresh || (h.feed != nil && h.podcast.sourceVersion() == h.version) {
+		return h.feed, nil
+	}
+
+	// broken config is not built on every request
+	if f := h.failure; f != nil && f.podcast.sourceVersion() == f.version && time.Since(f.time) < feedRetryInterval {
+		return h.stale(f.err)
+	}
+
+	podcast, err := New(h.configPath, h.options...)
+	version := podcast.sourceVersion()
+	if err != nil {
+		return h.fail(podcast, version, err)
+	}
+	// not cancelled with request: build is shared by all requests
+	if err := podcast.FixContext(context.Background()); err != nil {
+		return h.fail(podcast, version, err)
+	}
+	if err := podcast.Validate(); err != nil {
+		return h.fail(podcast, version, err)
+	}
+
+	feed, err := newServedFeed(podcast.Feed, h.Gzip)
+	if err != nil {
+		return h.fail(podcast, version, err)
+	}
+	h.failure = nil
+	h.podcast, h.version = podcast, version
+
+	// nothing but build date changed: keep served feed and its `Last-Modified`
+	if h.feed != nil && bytes.Equal(reLastBuildDate.ReplaceAll(feed.body, nil), reLastBuildDate.ReplaceAll(h.feed.body, nil)) {
+		return h.feed, nil
+	}
+
+	h.mu.Lock()
+	h.feed = feed
+	h.mu.Unlock()
+	return feed, nil
+}
+
+// Generate XML of `root` with `ETag`, `Last-Modified` and optional gzip
+func newServedFeed(root *XMLRoot, gz bool) (*servedFeed, error) {
+	var buf bytes.Buffer
+	if _, err := root.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+
+	feed := &servedFeed{
+		root:    root,
+		body:    buf.Bytes(),
+		modTime: root.LastBuildDate.Time,
+	}
+
+	sum := sha256.Sum256(feed.body)
+	feed.etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	if gz {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(feed.body)
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		feed.gzipBody = buf.Bytes()
+	}
+
+	return feed, nil
+}
+
+// Previous feed if there is one, otherwise `err`
+// Remember failed build of config files at `version`
+func (h *FeedHandler) fail(podcast *Podcast, version string, err error) (*servedFeed, error) {
+	h.failure = &feedFailure{
+		podcast: podcast,
+		version: version,
+		err:     err,
+		time:    time.Now(),
+	}
+	return h.stale(err)
+}
+
+func (h *FeedHandler) stale(err error) (*servedFeed, error) {
+	if h.feed == nil {
+		return nil, err
+	}
+	log.Printf("[podcast] Previous feed `%s` served. %s", h.configPath, err)
+	return h.feed, nil
+}
+
+// Remember app of feed request
+func (h *FeedHandler) countSubscriber(r *http.Request) {
+	userAgent := r.UserAgent()
+
+	h.subscribersMu.Lock()
+	defer h.subscribersMu.Unlock()
+
+	// aggregators fetch feed once for all their users
+	if m := reUserAgentSubscribers.FindStringSubmatch(userAgent); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		h.reported[userAgentProduct(userAgent)] = n
+		return
+	}
+
+	if h.UserAgents == nil {
+		return
+	}
+	client := h.UserAgents.Classify(userAgent)
+	if client.Bot {
+		return
+	}
+	if client.App == "" {
+		client.App = "Other"
+	}
+
+	now := time.Now()
+	h.clients[clientIP(r, h.TrustProxy)+"\n"+userAgent] = feedClient{
+		app:      client.App,
+		lastSeen: now,
+	}
+
+	if now.Sub(h.pruned) >= downloadWindow {
+		h.pruneClients(now)
+		h.pruned = now
+	}
+}
+
+// Forget clients not seen for 24 hours
+func (h *FeedHandler) pruneClients(now time.Time) {
+	for key, client := range h.clients {
+		if now.Sub(client.lastSeen) >= downloadWindow {
+			delete(h.clients, key)
+		}
+	}
+}
+
+// Subscribers - estimated subscribers by app: numbers reported by aggregators
+// and distinct clients (IP and user agent) which requested feed in the last 24 hours
+func (h *FeedHandler) Subscribers() map[string]int {
+	h.subscribersMu.Lock()
+	defer h.subscribersMu.Unlock()
+
+	subscribers := map[string]int{}
+	for app, n := range h.reported {
+		subscribers[app] += n
+	}
+
+	h.pruneClients(time.Now())
+	for _, client := range h.clients {
+		subscribers[client.app]++
+	}
+
+	return subscribers
+}
+
+// Name of the first product in user agent (`Feedly/1.0 (..)` ==> `Feedly`)
+func userAgentProduct(userAgent string) string {
+	fields := strings.Fields(userAgent)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.SplitN(fields[0], "/", 2)[0]
+}
+
+// Client accepts gzip encoded response
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(enc, ";")
+		if strings.TrimSpace(parts[0]) != "gzip" {
+			continue
+		}
+
+		// `gzip;q=0` refuses gzip
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Fingerprint of config files and episode files matching patterns (names, sizes and modification times).
+// Changes when any file loaded by `Load` is changed, added or removed
+func (podcast *Podcast) sourceVersion() string {
+	hash := sha256.New()
+
+	fpaths := podcast.sources
+	for _, pattern := range podcast.sourcePatterns {
+		matches, _ := globFiles(podcast.fsys, pattern)
+		fpaths = append(fpaths, matches...)
+	}
+
+	for _, fpath := range fpaths {
+		if info, err := statFile(podcast.fsys, fpath); err == nil {
+			fmt.Fprintf(hash, "%s %d %d\n", fpath, info.Size(), info.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(hash, "%s -\n", fpath)
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
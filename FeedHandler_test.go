@@ -0,0 +1,187 @@
+package podcast
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const testConfig = `Title: Podcast example
+Domain: https://example.xx
+Author: Neo and Trinity
+Owner: John, john@example.xx
+Description: Long description of this podcast.
+Summary: Very short description
+Image: /podcast.png
+SelfLink: /feed.xml
+Category: TV & Film, TV Reviews
+Items:
+    S01E01:
+        Title: First
+        Description: First episode
+        File: episodes/S01E01.mp3
+        PubDate: 2020-07-14
+        Duration: "52:11"
+`
+
+// Podcast config with one episode in memory
+func newTestFS(config string) fstest.MapFS {
+	return fstest.MapFS{
+		"podcast.yml":         {Data: []byte(config)},
+		"episodes/S01E01.mp3": {Data: []byte("ID3 episode")},
+	}
+}
+
+// Feed handler of config in memory with fixed build time
+func newTestFeedHandler(fsys fstest.MapFS) *FeedHandler {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	return NewFeedHandler("podcast.yml", WithFS(fsys), WithClock(func() time.Time { return now }))
+}
+
+func serveTest(h http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestFeedHandlerConditional(t *testing.T) {
+	h := newTestFeedHandler(newTestFS(testConfig))
+
+	w := serveTest(h, "/feed.xml", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "<title>First</title>") {
+		t.Fatalf("feed not served:\n%s", w.Body)
+	}
+	etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if etag == "" || lastModified != "Sat, 02 Jan 2021 03:04:05 GMT" {
+		t.Fatalf("unexpected ETag `%s` or Last-Modified `%s`", etag, lastModified)
+	}
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected int
+	}{
+		{"If-None-Match", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+		{"If-None-Match other", http.Header{"If-None-Match": {`"other"`}}, http.StatusOK},
+		{"If-Modified-Since", http.Header{"If-Modified-Since": {lastModified}}, http.StatusNotModified},
+		{"If-Modified-Since older", http.Header{"If-Modified-Since": {"Fri, 01 Jan 2021 00:00:00 GMT"}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serveTest(h, "/feed.xml", tt.header); w.Code != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestFeedHandlerGzip(t *testing.T) {
+	h := newTestFeedHandler(newTestFS(testConfig))
+	h.Gzip = true
+
+	plain := serveTest(h, "/feed.xml", nil)
+	gz := serveTest(h, "/feed.xml", http.Header{"Accept-Encoding": {"gzip, deflate"}})
+
+	if gz.Header().Get("Content-Encoding") != "gzip" || plain.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("unexpected Content-Encoding `%s` and `%s`", gz.Header().Get("Content-Encoding"), plain.Header().Get("Content-Encoding"))
+	}
+	if gz.Header().Get("ETag") == plain.Header().Get("ETag") {
+		t.Error("gzip variant must have own ETag")
+	}
+	if w := serveTest(h, "/feed.xml", http.Header{"Accept-Encoding": {"gzip;q=0"}}); w.Header().Get("Content-Encoding") != "" {
+		t.Error("gzip;q=0 must not be compressed")
+	}
+}
+
+func TestFeedHandlerMethod(t *testing.T) {
+	h := newTestFeedHandler(newTestFS(testConfig))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/feed.xml", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
+
+func TestFeedHandlerBrokenConfig(t *testing.T) {
+	fsys := newTestFS(testConfig)
+	h := newTestFeedHandler(fsys)
+
+	if w := serveTest(h, "/feed.xml", nil); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	// previous feed is served, broken config is not built again until changed
+	fsys["podcast.yml"] = &fstest.MapFile{Data: []byte(strings.Replace(testConfig, "Author: Neo and Trinity\n", "", 1))}
+	h.checked = time.Time{}
+	if w := serveTest(h, "/feed.xml", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Neo and Trinity") {
+		t.Fatalf("expected previous feed, got %d", w.Code)
+	}
+	failure := h.failure
+	if failure == nil {
+		t.Fatal("failed build not recorded")
+	}
+	h.checked = time.Time{}
+	serveTest(h, "/feed.xml", nil)
+	if h.failure != failure {
+		t.Error("broken config built again")
+	}
+
+	fsys["podcast.yml"] = &fstest.MapFile{Data: []byte(strings.Replace(testConfig, "Neo and Trinity", "Morpheus", 1))}
+	h.checked = time.Time{}
+	if w := serveTest(h, "/feed.xml", nil); !strings.Contains(w.Body.String(), "Morpheus") {
+		t.Error("fixed config not built")
+	}
+	if h.failure != nil {
+		t.Error("failure not cleared")
+	}
+}
+
+func TestFeedHandlerCheckInterval(t *testing.T) {
+	fsys := newTestFS(testConfig)
+	h := newTestFeedHandler(fsys)
+	serveTest(h, "/feed.xml", nil)
+
+	fsys["podcast.yml"] = &fstest.MapFile{Data: []byte(strings.Replace(testConfig, "Neo and Trinity", "Morpheus", 1))}
+	if w := serveTest(h, "/feed.xml", nil); strings.Contains(w.Body.String(), "Morpheus") {
+		t.Error("config checked again within interval")
+	}
+
+	// previous feed served while other request builds
+	h.checked = time.Time{}
+	h.building = true
+	if w := serveTest(h, "/feed.xml", nil); strings.Contains(w.Body.String(), "Morpheus") {
+		t.Error("request waited for build")
+	}
+
+	h.building = false
+	if w := serveTest(h, "/feed.xml", nil); !strings.Contains(w.Body.String(), "Morpheus") {
+		t.Error("changed config not built after interval")
+	}
+}
+
+func TestFeedHandlerCanceledRequest(t *testing.T) {
+	h := newTestFeedHandler(newTestFS(testConfig))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed.xml", nil).WithContext(ctx))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+	if h.failure != nil {
+		t.Errorf("canceled request recorded as failed build: %s", h.failure.err)
+	}
+}
@@ -0,0 +1,109 @@
+package podcast
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildAllFileCollisions(t *testing.T) {
+	tests := []struct {
+		name  string
+		feeds string
+		err   string
+	}{
+		{"distinct", "    all:\n        Title: All\n    s1:\n        SelfLink: /season-1.xml\n", ""},
+		{"key as main feed", "    feed:\n        Title: Other\n", "Feed[feed] file `feed.xml` is already used by main feed"},
+		{"same self link", "    a:\n        SelfLink: /a/rss.xml\n    b:\n        SelfLink: /b/rss.xml\n", "Feed[b] file `rss.xml` is already used by Feed[a]"},
+		{"same file", "    a:\n        File: x.xml\n    b:\n        File: ./x.xml\n", "Feed[b] file `x.xml` is already used by Feed[a]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := newTestFS(testConfig + "Feeds:\n" + tt.feeds)
+			p, err := New("podcast.yml", WithFS(fsys))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir, err := ioutil.TempDir("", "podcast")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			err = p.BuildAll(dir)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Fatalf("expected error `%s`, got %v", tt.err, err)
+			}
+			if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) > 0 {
+				t.Errorf("no feed must be saved, found %v", files)
+			}
+		})
+	}
+}
+
+func TestFeedDeriveSelfLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		selfLink string
+		feed     *Feed
+		expected string
+	}{
+		{"own self link", "/feed.xml", &Feed{Key: "s3", SelfLink: &AttrHref{Href: "/season-3.xml"}}, "https://example.xx/season-3.xml"},
+		{"file next to main feed", "/podcast/feed.xml", &Feed{Key: "spanish", File: "feed-es.xml"}, "https://example.xx/podcast/feed-es.xml"},
+		{"key next to main feed", "/feed.xml", &Feed{Key: "spanish"}, "https://example.xx/spanish.xml"},
+		{"no main self link", "", &Feed{Key: "spanish", File: "./es/feed.xml"}, "https://example.xx/es/feed.xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := &Channel{Domain: "https://example.xx"}
+			if tt.selfLink != "" {
+				channel.SelfLink = &AttrHref{Href: pathToURL(channel.Domain, tt.selfLink)}
+				channel.PodcastGUID = PodcastGUID(channel.SelfLink.Href)
+			}
+
+			derived := tt.feed.Derive(channel)
+			if derived.SelfLink.IsEmpty() || derived.SelfLink.Href != tt.expected {
+				t.Fatalf("expected self link `%s`, got %+v", tt.expected, derived.SelfLink)
+			}
+			if derived.PodcastGUID != PodcastGUID(tt.expected) || derived.PodcastGUID == channel.PodcastGUID {
+				t.Errorf("derived feed must have own podcast:guid, got `%s`", derived.PodcastGUID)
+			}
+			if channel.SelfLink != nil && channel.SelfLink.Href != pathToURL(channel.Domain, tt.selfLink) {
+				t.Errorf("main self link changed: %s", channel.SelfLink.Href)
+			}
+		})
+	}
+}
+
+func TestBuildAllInvalidFeed(t *testing.T) {
+	fsys := newTestFS(testConfig + "Feeds:\n    bad:\n        Seasons: [9]\n")
+	p, err := New("podcast.yml", WithFS(fsys))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "podcast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := p.BuildAll(dir); err == nil || !strings.HasPrefix(err.Error(), "Feed[bad] No episodes found") {
+		t.Fatalf("expected error of feed `bad`, got %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) > 0 {
+		t.Errorf("no feed must be saved, found %v", files)
+	}
+}
@@ -0,0 +1,127 @@
+package podcast
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Extensions of episode files accepted in `Items:` patterns
+var itemFileExtensions = []string{".yml", ".yaml", ".json", ".toml", ".md", ".markdown"}
+
+// Load all episode files matching `patterns`.
+//
+//	Items: episodes/*.yml
+//	Items:
+//	    - episodes/*.yml
+//	    - bonus/*.md
+func (d *configDecoder) loadItemFiles(patterns []string) (ItemList, error) {
+	var items ItemList
+	keys := map[string]string{}
+
+	for _, pattern := range patterns {
+		d.sourcePatterns = append(d.sourcePatterns, pattern)
+
+		fpaths, err := globFiles(d.fsys, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Items: invalid pattern `%s`: %s", pattern, err)
+		}
+		if len(fpaths) == 0 {
+			log.Printf("Warning: No episode files match `%s`", pattern)
+		}
+
+		for _, fpath := range fpaths {
+			ext := strings.ToLower(filepath.Ext(fpath))
+			if !inSlice(ext, itemFileExtensions) {
+				continue
+			}
+
+			item, err := d.loadItemFile(fpath)
+			if err != nil {
+				return nil, err
+			}
+
+			if prev, ok := keys[item.Key]; ok {
+				return nil, fmt.Errorf("%s: duplicate item key `%s` already defined in %s", fpath, item.Key, prev)
+			}
+			keys[item.Key] = fpath
+
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// Load single episode from YAML, JSON, TOML file or Markdown file with YAML front matter.
+// File name without extension becomes `Item.Key`
+func (d *configDecoder) loadItemFile(fpath string) (*Item, error) {
+	buf, err := readFile(d.fsys, fpath)
+	if err != nil {
+		return nil, err
+	}
+	d.sources = append(d.sources, fpath)
+
+	ext := strings.ToLower(filepath.Ext(fpath))
+
+	var body string
+	if ext == ".md" || ext == ".markdown" {
+		buf, body, err = splitFrontMatter(buf)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", fpath, err)
+		}
+	}
+
+	node, err := d.parse(fpath, buf, configFormat(fpath))
+	if err != nil {
+		return nil, err
+	}
+
+	item := &Item{}
+	item.Key = strings.TrimSuffix(filepath.Base(fpath), filepath.Ext(fpath))
+	d.decode(node, reflect.ValueOf(item).Elem(), "Items."+item.Key)
+
+	// Markdown content is episode description
+	if item.Description.IsEmpty() && body != "" {
+		item.Description = &CDATA{Text: body}
+	}
+
+	item.ExtractKeyInfo()
+
+	return item, nil
+}
+
+// Split Markdown file into YAML front matter and content.
+// Front matter keeps empty first line so line numbers match the file.
+//
+//	---
+//	Title: Episode title
+//	---
+//	Content
+func splitFrontMatter(buf []byte) ([]byte, string, error) {
+	buf = bytes.ReplaceAll(buf, []byte("\r\n"), []byte("\n"))
+
+	delim := []byte("---")
+	if !bytes.HasPrefix(buf, append(delim, '\n')) {
+		return nil, "", fmt.Errorf("YAML front matter must start with `---` on the first line")
+	}
+	buf = buf[len(delim):]
+
+	end := bytes.Index(buf, []byte("\n---"))
+	if end == -1 {
+		return nil, "", fmt.Errorf("YAML front matter must end with `---`")
+	}
+
+	front := buf[:end+1]
+	body := buf[end+len("\n---"):]
+	if i := bytes.IndexByte(body, '\n'); i != -1 {
+		body = body[i+1:]
+	} else {
+		body = nil
+	}
+
+	return front, strings.TrimSpace(string(body)), nil
+}
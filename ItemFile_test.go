@@ -0,0 +1,50 @@
+package podcast
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestItemFilesRelativeToDeclaringConfig(t *testing.T) {
+	episode := []byte("Title: First\nDescription: First episode\nFile: shared/episodes/S01E01.mp3\nPubDate: 2020-07-14\nDuration: 60\n")
+	fsys := fstest.MapFS{
+		"shared/base.yml":             {Data: []byte("Author: Neo\nItems: episodes/*.yml\n")},
+		"shared/episodes/S01E01.yml":  {Data: episode},
+		"shared/episodes/S01E01.mp3":  {Data: []byte("ID3 episode")},
+		"shows/podcast.yml":           {Data: []byte("Extends: ../shared/base.yml\nTitle: Podcast\n")},
+		"shows/episodes/S09E09.yml":   {Data: episode},
+		"shows/other.yml":             {Data: []byte("Extends: ../shared/base.yml\nItems: [episodes/*.yml]\n")},
+		"shows/episodes/S09E10.yml":   {Data: episode},
+		"shows/episodes/ignored.json": {Data: []byte("{}")},
+	}
+
+	tests := []struct {
+		config string
+		keys   []string
+	}{
+		{"shows/podcast.yml", []string{"S01E01"}},
+		{"shows/other.yml", []string{"S09E10", "S09E09"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			p, err := New(tt.config, WithFS(fsys))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var keys []string
+			for _, item := range p.Episodes() {
+				keys = append(keys, item.Key)
+			}
+			if len(keys) != len(tt.keys) {
+				t.Fatalf("expected items %v, got %v", tt.keys, keys)
+			}
+			for i := range keys {
+				if keys[i] != tt.keys[i] {
+					t.Errorf("expected items %v, got %v", tt.keys, keys)
+				}
+			}
+		})
+	}
+}
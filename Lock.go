@@ -0,0 +1,115 @@
+package podcast
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName - default lock file saved next to config file
+const LockFileName = "podcast.lock"
+
+// GUIDLock - generated GUIDs pinned on the first build so they never change,
+// even if feed or enclosure URLs change. Keep lock file in version control.
+type GUIDLock struct {
+	PodcastGUID string            `yaml:"PodcastGUID,omitempty"`
+	Items       map[string]string `yaml:"Items,omitempty"` // by item key
+
+	fpath   string
+	existed bool // lock file was found
+	changed bool
+}
+
+// LoadGUIDLock - load lock from file. Missing file gives empty lock
+func LoadGUIDLock(fpath string) (*GUIDLock, error) {
+	lock := &GUIDLock{
+		Items: map[string]string{},
+		fpath: fpath,
+	}
+
+	buf, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, err
+	}
+
+	lock.existed = true
+	if err := yaml.Unmarshal(buf, lock); err != nil {
+		return nil, err
+	}
+	if lock.Items == nil {
+		lock.Items = map[string]string{}
+	}
+
+	return lock, nil
+}
+
+// Use pinned GUIDs for channel and items without GUID in config
+func (lock *GUIDLock) apply(channel *Channel) {
+	if lock == nil {
+		return
+	}
+
+	if channel.PodcastGUID == "" {
+		channel.PodcastGUID = lock.PodcastGUID
+	}
+
+	for _, item := range channel.Items {
+		if guid := lock.Items[item.Key]; guid != "" && item.GUID.IsEmpty() {
+			item.GUID = NewGUID(guid)
+		}
+	}
+}
+
+// Pin GUIDs generated in this build
+func (lock *GUIDLock) pin(channel *Channel) {
+	if lock == nil {
+		return
+	}
+
+	if lock.PodcastGUID == "" && channel.PodcastGUID != "" {
+		lock.PodcastGUID = channel.PodcastGUID
+		lock.changed = true
+	}
+
+	for _, item := range channel.Items {
+		if _, ok := lock.Items[item.Key]; !ok && !item.GUID.IsEmpty() {
+			lock.Items[item.Key] = item.GUID.Text
+			lock.changed = true
+		}
+	}
+}
+
+// Save lock to file if new GUIDs were pinned
+func (lock *GUIDLock) Save() error {
+	if lock == nil || lock.fpath == "" || !lock.changed {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# GUIDs pinned on the first build. Keep this file in version control\n")
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(lock); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	_, err := writeFileAtomic(lock.fpath, 0644, func(w io.Writer) error {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}, nil)
+	if err == nil {
+		lock.existed = true
+		lock.changed = false
+	}
+	return err
+}
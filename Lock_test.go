@@ -0,0 +1,70 @@
+package podcast
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLockPinsPodcastGUID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "podcast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for fpath, file := range newTestFS(testConfig) {
+		fpath = filepath.Join(dir, fpath)
+		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fpath, file.Data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configPath := filepath.Join(dir, "podcast.yml")
+	build := func() string {
+		t.Helper()
+		p, err := New(configPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.FixContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return p.Feed.Channel.PodcastGUID
+	}
+
+	guid := build()
+	if guid != PodcastGUID("https://example.xx/feed.xml") {
+		t.Fatalf("unexpected podcast:guid %s", guid)
+	}
+	if _, err := os.Stat(filepath.Join(dir, LockFileName)); err != nil {
+		t.Fatalf("lock file not saved by default: %s", err)
+	}
+
+	// moved feed keeps its podcast:guid
+	config := strings.Replace(testConfig, "SelfLink: /feed.xml", "SelfLink: /podcast/feed.xml", 1)
+	if err := ioutil.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := build(); got != guid {
+		t.Errorf("podcast:guid changed with SelfLink: %s -> %s", guid, got)
+	}
+
+	// without lock file it follows SelfLink
+	p, err := New(configPath, WithLockFile(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.FixContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if p.Feed.Channel.PodcastGUID == guid {
+		t.Error("lock file used when disabled")
+	}
+}
@@ -0,0 +1,31 @@
+package podcast
+
+import "strings"
+
+// MovedFeed - minimal feed for old location of moved podcast.
+// Only identity of show and `itunes:new-feed-url` are kept so apps move subscribers to `NewFeedURL`.
+func (feed *XMLRoot) MovedFeed() *XMLRoot {
+	channel := feed.Channel
+	return feed.WithChannel(&Channel{
+		SelfLink:       channel.SelfLink,
+		NewFeedURL:     channel.NewFeedURL,
+		Link:           channel.Link,
+		Title:          channel.Title,
+		Language:       channel.Language,
+		Description:    channel.Description,
+		Image:          channel.Image,
+		ItunesTitle:    channel.ItunesTitle,
+		ItunesAuthor:   channel.ItunesAuthor,
+		ItunesOwner:    channel.ItunesOwner,
+		ItunesExplicit: channel.ItunesExplicit,
+		ItunesImage:    channel.ItunesImage,
+		Country:        channel.Country,
+		PodcastGUID:    channel.PodcastGUID,
+		LastBuildDate:  channel.LastBuildDate,
+	})
+}
+
+// Feed URLs are the same ignoring scheme and trailing slashes
+func sameFeedURL(a, b string) bool {
+	return strings.TrimRight(trimScheme(a), "/") == strings.TrimRight(trimScheme(b), "/")
+}
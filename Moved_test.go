@@ -0,0 +1,93 @@
+package podcast
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeedHandlerRedirects(t *testing.T) {
+	signer := newTestSigner(t, "secret")
+	token := signer.Token("john@example.xx", time.Time{})
+	moved := strings.Replace(testConfig, "SelfLink: /feed.xml\n", "SelfLink: /feed.xml\nNewFeedURL: https://new.example.xx/feed.xml\n", 1)
+
+	tests := []struct {
+		name     string
+		config   string
+		signer   *FeedSigner
+		target   string
+		expected int
+		location string
+	}{
+		{"served", testConfig, nil, "http://example.xx/feed.xml", http.StatusOK, ""},
+		{"old path", testConfig, nil, "http://example.xx/old/rss.xml", http.StatusMovedPermanently, "https://example.xx/feed.xml"},
+		{"old path with query", testConfig, nil, "http://example.xx/old/rss.xml?a=1", http.StatusMovedPermanently, "https://example.xx/feed.xml?a=1"},
+		{"moved", moved, nil, "http://example.xx/feed.xml", http.StatusMovedPermanently, "https://new.example.xx/feed.xml"},
+		{"moved old path", moved, nil, "http://example.xx/old/rss.xml", http.StatusMovedPermanently, "https://new.example.xx/feed.xml"},
+		{"moved served at new location", moved, nil, "http://new.example.xx/feed.xml", http.StatusOK, ""},
+		{"private moved without token", moved, signer, "http://example.xx/feed.xml", http.StatusUnauthorized, ""},
+		{"private moved with invalid token", moved, signer, "http://example.xx/feed.xml?token=x.0.y", http.StatusForbidden, ""},
+		{"private moved with token", moved, signer, "http://example.xx/feed.xml?token=" + token, http.StatusMovedPermanently, "https://new.example.xx/feed.xml?token=" + token},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestFeedHandler(newTestFS(tt.config))
+			h.OldPaths = []string{"old/rss.xml"}
+			h.Signer = tt.signer
+
+			w := serveTest(h, tt.target, nil)
+			if w.Code != tt.expected || w.Header().Get("Location") != tt.location {
+				t.Errorf("expected %d `%s`, got %d `%s`", tt.expected, tt.location, w.Code, w.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestMovedFeed(t *testing.T) {
+	fsys := newTestFS(strings.Replace(testConfig, "SelfLink: /feed.xml\n", "SelfLink: /feed.xml\nNewFeedURL: /new/feed.xml\n", 1))
+	p, err := New("podcast.yml", WithFS(fsys), WithMovedFeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Fix()
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	xml, err := p.Feed.MovedFeed().ToXML("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(xml), "<itunes:new-feed-url>https://example.xx/new/feed.xml</itunes:new-feed-url>") {
+		t.Errorf("new feed URL missing:\n%s", xml)
+	}
+	if strings.Contains(string(xml), "<item>") {
+		t.Errorf("moved feed must not have episodes:\n%s", xml)
+	}
+}
+
+func TestNewFeedURLValidate(t *testing.T) {
+	tests := []struct {
+		newFeedURL string
+		valid      bool
+	}{
+		{"https://new.example.xx/feed.xml", true},
+		{"/podcast/feed.xml", true},
+		{"https://example.xx/feed.xml", false},
+		{"http://example.xx/feed.xml/", false},
+	}
+
+	for _, tt := range tests {
+		fsys := newTestFS(strings.Replace(testConfig, "SelfLink: /feed.xml\n", "SelfLink: /feed.xml\nNewFeedURL: "+tt.newFeedURL+"\n", 1))
+		p, err := New("podcast.yml", WithFS(fsys))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Fix()
+		if err := p.Validate(); (err == nil) != tt.valid {
+			t.Errorf("NewFeedURL `%s`: unexpected validation result %v", tt.newFeedURL, err)
+		}
+	}
+}
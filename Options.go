@@ -0,0 +1,133 @@
+package podcast
+
+import (
+	"io/fs"
+	"runtime"
+	"time"
+)
+
+// Option - podcast setting given to `New`
+type Option func(*Podcast)
+
+// WithFormat - config file format `FormatYAML`, `FormatJSON` or `FormatTOML`.
+// By default detected by file extension.
+func WithFormat(format string) Option {
+	return func(podcast *Podcast) {
+		podcast.format = format
+	}
+}
+
+// WithFS - file system for config, episode and media files (`embed.FS`, `zip.Reader`, `fstest.MapFS`, ..).
+// Paths in config are relative to root of `fsys`. Local file system is used by default.
+// Duration of episodes can't be detected by external tools on other file systems.
+func WithFS(fsys fs.FS) Option {
+	return func(podcast *Podcast) {
+		podcast.fsys = fsys
+		podcast.Feed.Channel.fsys = fsys
+	}
+}
+
+// WithWorkers - number of items fixed (media probed) at the same time. Number of CPUs by default
+func WithWorkers(n int) Option {
+	return func(podcast *Podcast) {
+		if n < 1 {
+			n = runtime.NumCPU()
+		}
+		podcast.Feed.Channel.workers = n
+	}
+}
+
+// WithProgress - called after each item is fixed
+func WithProgress(progress ProgressFunc) Option {
+	return func(podcast *Podcast) {
+		podcast.Feed.Channel.progress = progress
+	}
+}
+
+// WithCache - media cache file to keep probe results of episode files between builds.
+// `.podcast-cache.json` next to config file by default. Empty path disables cache.
+func WithCache(fpath string) Option {
+	return func(podcast *Podcast) {
+		podcast.cacheFile = fpath
+	}
+}
+
+// WithContentHash - keep SHA-256 of episode files in `Item.FileChecksum` and media cache.
+// Cache entries stay valid for touched but unchanged files.
+func WithContentHash() Option {
+	return func(podcast *Podcast) {
+		podcast.cacheHash = true
+	}
+}
+
+// WithIndent - indentation of generated XML. Empty `prefix` and `indent` gives minified XML
+func WithIndent(prefix, indent string) Option {
+	return func(podcast *Podcast) {
+		podcast.Feed.SetIndent(prefix, indent)
+	}
+}
+
+// WithClock - time of build used for `LastBuildDate`. Overrides `SOURCE_DATE_EPOCH`.
+// Fixed time gives identical feed for the same config.
+func WithClock(now func() time.Time) Option {
+	return func(podcast *Podcast) {
+		podcast.Feed.Channel.clock = now
+	}
+}
+
+// WithBuildDateFromItems - `LastBuildDate` from publish date of the newest item instead of time of build
+func WithBuildDateFromItems() Option {
+	return func(podcast *Podcast) {
+		podcast.Feed.Channel.buildDateFromItems = true
+	}
+}
+
+// WithPublishedFeed - fail `SaveToFile`, `SaveIfChanged` and `BuildAll` if GUIDs or enclosure URLs changed or episodes were removed
+// versus previously published feed (file or URL). Changes of items given by key or old GUID
+// in `acknowledged` (`*` for all) are allowed.
+func WithPublishedFeed(location string, acknowledged ...string) Option {
+	return func(podcast *Podcast) {
+		podcast.publishedFeed = location
+		podcast.acknowledged = acknowledged
+	}
+}
+
+// WithFirstBuild - missing published feed of `WithPublishedFeed` is not an error, for the first build of feed
+func WithFirstBuild() Option {
+	return func(podcast *Podcast) {
+		podcast.firstBuild = true
+	}
+}
+
+// WithLockFile - lock file pinning generated `podcast:guid` and item GUIDs on the first build,
+// so they survive domain moves. `podcast.lock` next to config file by default.
+// Empty path disables lock file.
+func WithLockFile(fpath string) Option {
+	return func(podcast *Podcast) {
+		podcast.lockFile = fpath
+	}
+}
+
+// WithUUIDGUIDs - GUIDs of new items without one as UUIDv5 of `PodcastGUID` and item key instead of enclosure URL.
+// If lock file doesn't exist yet current GUIDs of all items are pinned, so enabling it
+// for existing show changes no GUIDs. Create empty lock file to get UUIDs for all items.
+func WithUUIDGUIDs() Option {
+	return func(podcast *Podcast) {
+		podcast.Feed.Channel.uuidGUIDs = true
+	}
+}
+
+// WithMovedFeed - save minimal redirect feeds for old location pointing to `NewFeedURL`
+// instead of full feeds. `NewFeedURL` is required.
+func WithMovedFeed() Option {
+	return func(podcast *Podcast) {
+		podcast.moved = true
+	}
+}
+
+// WithDryRun - media cache and lock file are read but never written. For previews of feed
+func WithDryRun() Option {
+	return func(podcast *Podcast) {
+		podcast.dryRun = true
+	}
+}
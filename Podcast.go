@@ -1,45 +1,98 @@
 package podcast
 
 import (
-	"io/ioutil"
-	"os"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"time"
-
-	"gopkg.in/yaml.v2"
 )
 
 // Podcast ..
 type Podcast struct {
 	configFilepath string `yaml:"-"`
+	format         string `yaml:"-"`
+	fsys           fs.FS
+
+	// media cache file. Empty to disable
+	cacheFile string
+	cacheHash bool
+
+	// generated GUIDs pinned in lock file. Empty to disable
+	lockFile string
+	lock     *GUIDLock
+
+	// previously published feed to check GUID and enclosure changes against
+	publishedFeed string
+	acknowledged  []string
+	firstBuild    bool // published feed may be missing
+
+	// save redirect feeds pointing to `NewFeedURL` instead of full feeds
+	moved bool
+
+	// media cache and lock file are not written
+	dryRun bool
+
+	// config files read by `Load` and patterns of episode files
+	sources        []string
+	sourcePatterns []string
 
 	Title string `yaml:"Title"`
 
 	Feed *XMLRoot `yaml:"-"`
 }
 
-// New ..
-func New(configPath string) (*Podcast, error) {
+// New - load podcast from YAML, JSON or TOML config file
+func New(configPath string, options ...Option) (*Podcast, error) {
+	podcast := newPodcast(configPath, options...)
+
+	// load content from given directory
+	if err := podcast.Load(); err != nil {
+		return podcast, err
+	}
+
+	return podcast, nil
+}
+
+// podcast with empty channel
+func newPodcast(configPath string, options ...Option) *Podcast {
 	podcast := &Podcast{
 		configFilepath: configPath,
+		fsys:           osFS{},
 
 		Feed: &XMLRoot{
-			Itunes:        "http://www.itunes.com/dtds/podcast-1.0.dtd",
-			Spotify:       "https://www.spotify.com/ns/rss",
-			Content:       "http://purl.org/rss/1.0/modules/content/",
-			Atom:          "http://www.w3.org/2005/Atom",
-			Version:       "2.0",
-			Channel:       &Channel{},
-			Generator:     "https://github.com/briiC/podcast",
-			LastBuildDate: Date{time.Now()},
+			Itunes:    "http://www.itunes.com/dtds/podcast-1.0.dtd",
+			Spotify:   "https://www.spotify.com/ns/rss",
+			Content:   "http://purl.org/rss/1.0/modules/content/",
+			Atom:      "http://www.w3.org/2005/Atom",
+			Podcast:   "https://podcastindex.org/namespace/1.0",
+			Version:   "2.0",
+			Channel:   &Channel{fsys: osFS{}},
+			Generator: "https://github.com/briiC/podcast",
 		},
 	}
 
-	// load content from given directory
-	if err := podcast.Load(); err != nil {
-		return podcast, err
+	// cache and lock file next to config file by default
+	podcast.cacheFile = filepath.Join(filepath.Dir(configPath), CacheFileName)
+	podcast.lockFile = filepath.Join(filepath.Dir(configPath), LockFileName)
+
+	// fixed build time for reproducible builds
+	if t, ok := sourceDateEpoch(); ok {
+		podcast.Feed.Channel.clock = func() time.Time { return t }
 	}
 
-	return podcast, nil
+	for _, option := range options {
+		option(podcast)
+	}
+
+	podcast.Feed.LastBuildDate = Date{podcast.Feed.Channel.now()}
+
+	return podcast
 }
 
 // Episodes - quickly get items
@@ -54,23 +107,44 @@ func (podcast *Podcast) XML() ([]byte, error) {
 
 // Load ..
 func (podcast *Podcast) Load() error {
+	decoder := newConfigDecoder(podcast.fsys)
+
+	// files read so far are kept on errors too, to notice when broken config is fixed
+	defer func() {
+		podcast.sources = append([]string{podcast.configFilepath}, decoder.sources...)
+		podcast.sourcePatterns = decoder.sourcePatterns
+	}()
 
 	// check if podcast YAML file exists
-	if _, err := os.Stat(podcast.configFilepath); os.IsNotExist(err) {
+	if _, err := statFile(podcast.fsys, podcast.configFilepath); err != nil {
 		return err
 	}
 
-	// Read YAML contents
-	buf, err := ioutil.ReadFile(podcast.configFilepath)
+	// Read YAML contents with variables and base config resolved
+	root, err := decoder.read(podcast.configFilepath, podcast.format, nil)
 	if err != nil {
 		return err
 	}
 
 	// Parse YAML into struct
-	if err := yaml.Unmarshal(buf, &podcast.Feed.Channel); err != nil {
+	channel := podcast.Feed.Channel
+	decoder.decode(root, reflect.ValueOf(channel).Elem(), "")
+
+	// `Items:` can be glob pattern(s) to episode files instead of inline items
+	if len(decoder.itemPatterns) > 0 {
+		items, err := decoder.loadItemFiles(decoder.itemPatterns)
+		if err != nil {
+			return err
+		}
+		channel.Items = append(channel.Items, items...)
+	}
+
+	if err := decoder.err(); err != nil {
 		return err
 	}
 
+	sort.Sort(channel.Items)
+
 	// podcast.Feed.Channel.Title = podcast.Title
 
 	// color.Magenta("%+v", podcast.Feed.Channel)
@@ -79,8 +153,51 @@ func (podcast *Podcast) Load() error {
 
 // Fix misconfigs and populate empty values with defaults  before saving ..
 func (podcast *Podcast) Fix() {
+	podcast.FixContext(context.Background())
+}
+
+// FixContext - fix podcast. Stops processing items when `ctx` is done
+func (podcast *Podcast) FixContext(ctx context.Context) error {
 	// log.Println("[podcast] Fix ")
-	podcast.Feed.Channel.Fix()
+	channel := podcast.Feed.Channel
+
+	// media cache is kept only for local files
+	if channel.cache == nil && podcast.cacheFile != "" && isOSFS(podcast.fsys) {
+		channel.cache = LoadMediaCache(podcast.cacheFile, podcast.cacheHash)
+	}
+
+	// pinned GUIDs are kept only for local files
+	if podcast.lock == nil && podcast.lockFile != "" && isOSFS(podcast.fsys) {
+		lock, err := LoadGUIDLock(podcast.lockFile)
+		if err != nil {
+			return fmt.Errorf("%s: %s", podcast.lockFile, err)
+		}
+		podcast.lock = lock
+	}
+	podcast.lock.apply(channel)
+
+	// the first build with lock file pins current GUIDs of existing show
+	channel.keepURLGUIDs = podcast.lock != nil && !podcast.lock.existed
+
+	if err := channel.FixContext(ctx); err != nil {
+		return err
+	}
+	podcast.Feed.LastBuildDate = *channel.LastBuildDate
+
+	podcast.lock.pin(channel)
+	if podcast.dryRun {
+		return nil
+	}
+
+	if err := podcast.lock.Save(); err != nil {
+		return fmt.Errorf("%s: %s", podcast.lockFile, err)
+	}
+
+	if err := channel.cache.Save(); err != nil {
+		log.Printf("Warning: Couldn't save media cache `%s`. %s", podcast.cacheFile, err)
+	}
+
+	return nil
 }
 
 // Validate before saving ..
@@ -91,25 +208,169 @@ func (podcast *Podcast) Validate() error {
 		return err
 	}
 
+	if podcast.moved && podcast.Feed.Channel.NewFeedURL == "" {
+		return fmt.Errorf("Moved feed requires `NewFeedURL`")
+	}
+
 	return nil
 }
 
+// Check feed against published feed given in `WithPublishedFeed`.
+// Done once per build, not in `Validate` as published feed may be downloaded
+func (podcast *Podcast) checkPublishedFeed() error {
+	if podcast.publishedFeed == "" {
+		return nil
+	}
+
+	published, err := LoadPublishedFeed(podcast.publishedFeed)
+	if errors.Is(err, fs.ErrNotExist) && podcast.firstBuild {
+		log.Printf("Warning: Published feed `%s` not found, changes not checked", podcast.publishedFeed)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Published feed: %s", err)
+	}
+	return podcast.CheckPublished(published, podcast.acknowledged...)
+}
+
 // SaveToFile ..
 func (podcast *Podcast) SaveToFile(fpath string) error {
+	return podcast.SaveToFileContext(context.Background(), fpath)
+}
+
+// SaveToFileContext - save feed to file. Stops when `ctx` is done
+func (podcast *Podcast) SaveToFileContext(ctx context.Context, fpath string) error {
+	_, err := podcast.SaveIfChangedContext(ctx, fpath)
+	return err
+}
+
+// SaveIfChanged - save feed to file only if content (except build date) changed.
+// Returns true if file was written.
+func (podcast *Podcast) SaveIfChanged(fpath string) (bool, error) {
+	return podcast.SaveIfChangedContext(context.Background(), fpath)
+}
+
+// SaveIfChangedContext - save feed to file only if changed. Stops when `ctx` is done
+func (podcast *Podcast) SaveIfChangedContext(ctx context.Context, fpath string) (bool, error) {
 	// fix some values
-	podcast.Fix()
+	if err := podcast.FixContext(ctx); err != nil {
+		return false, err
+	}
 
 	// validate feed before saving to file
 	if err := podcast.Validate(); err != nil {
+		return false, err
+	}
+	if err := podcast.checkPublishedFeed(); err != nil {
+		return false, err
+	}
+
+	return saveFeed(podcast.output(podcast.Feed), fpath)
+}
+
+// BuildAll - save main feed and all feeds from `Feeds:` into `dir`
+func (podcast *Podcast) BuildAll(dir string) error {
+	return podcast.BuildAllContext(context.Background(), dir)
+}
+
+// BuildAllContext - save all feeds. Stops when `ctx` is done
+func (podcast *Podcast) BuildAllContext(ctx context.Context, dir string) error {
+	// fix some values
+	if err := podcast.FixContext(ctx); err != nil {
 		return err
 	}
 
-	// generate XML and save to file
-	buf, err := podcast.Feed.ToXML("")
-	if err != nil {
+	// validate main feed before saving any file
+	if err := podcast.Validate(); err != nil {
 		return err
 	}
+	if err := podcast.checkPublishedFeed(); err != nil {
+		return err
+	}
+
+	channel := podcast.Feed.Channel
+	mainFile := feedFileName(channel.SelfLink, "feed.xml")
+
+	// sorted keys to always build in the same order
+	keys := make([]string, 0, len(channel.Feeds))
+	for key := range channel.Feeds {
+		if channel.Feeds[key] != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// all feeds are derived and validated before saving any file
+	fpaths := []string{filepath.Join(dir, mainFile)}
+	feeds := []*XMLRoot{podcast.output(podcast.Feed)}
+	files := map[string]string{filepath.Clean(mainFile): "main feed"}
+	for _, key := range keys {
+		feed := channel.Feeds[key]
+		feed.Key = key
+
+		// feeds must not overwrite each other
+		fname := filepath.Clean(feed.FileName())
+		if other, ok := files[fname]; ok {
+			return fmt.Errorf("Feed[%s] file `%s` is already used by %s. Set `File` or `SelfLink`", key, fname, other)
+		}
+		files[fname] = fmt.Sprintf("Feed[%s]", key)
+
+		derived := feed.Derive(channel)
+		if err := derived.Validate(); err != nil {
+			return fmt.Errorf("Feed[%s] %s", key, err)
+		}
+		if podcast.moved && derived.NewFeedURL == "" {
+			return fmt.Errorf("Feed[%s] Moved feed requires `NewFeedURL`", key)
+		}
+
+		fpaths = append(fpaths, filepath.Join(dir, fname))
+		feeds = append(feeds, podcast.output(podcast.Feed.WithChannel(derived)))
+	}
+
+	for i, feed := range feeds {
+		if _, err := saveFeed(feed, fpaths[i]); err != nil {
+			if i == 0 {
+				return err
+			}
+			return fmt.Errorf("Feed[%s] %s", keys[i-1], err)
+		}
+	}
+
+	return nil
+}
+
+// Feed to save: redirect feed if podcast moved
+func (podcast *Podcast) output(feed *XMLRoot) *XMLRoot {
+	if podcast.moved {
+		return feed.MovedFeed()
+	}
+	return feed
+}
+
+// Generate XML and save to file if content changed.
+// File is replaced at once so web server never serves half-written feed.
+// If nothing changed build date of feed is taken from existing file.
+func saveFeed(feed *XMLRoot, fpath string) (bool, error) {
+	var buildDate *Date
+
+	written, err := writeFileAtomic(fpath, 0640, func(w io.Writer) error {
+		_, err := feed.WriteTo(w)
+		return err
+	}, func(tmpPath string) bool {
+		same, date := sameFeedFiles(fpath, tmpPath)
+		buildDate = date
+		return !same
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if !written && !buildDate.IsZero() {
+		feed.LastBuildDate = *buildDate
+		if feed.Channel != nil {
+			feed.Channel.LastBuildDate = &Date{buildDate.Time}
+		}
+	}
 
-	// save to file
-	return ioutil.WriteFile(fpath, buf, 0640)
+	return written, nil
 }
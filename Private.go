@@ -0,0 +1,230 @@
+package podcast
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTokenParam - query parameter of subscriber token in private feed URLs
+const DefaultTokenParam = "token"
+
+// MinSecretLength - bytes required for secret of `FeedSigner`
+const MinSecretLength = 32
+
+// Token errors
+var (
+	ErrTokenInvalid = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+	ErrTokenRevoked = errors.New("token revoked")
+)
+
+// FeedSigner - signs feed and enclosure URLs of private feeds with per-subscriber tokens.
+// Token holds subscriber ID and expiry signed with HMAC-SHA256.
+type FeedSigner struct {
+	// Param - query parameter of token. `token` by default
+	Param string
+
+	// Revoked - revoked subscribers and tokens. Optional
+	Revoked *RevocationList
+
+	secret []byte
+}
+
+// NewFeedSigner - signer with secret key shared by all servers verifying tokens.
+// Secret must be random and at least `MinSecretLength` bytes long
+func NewFeedSigner(secret []byte) (*FeedSigner, error) {
+	if len(secret) < MinSecretLength {
+		return nil, fmt.Errorf("Secret of %d bytes is too short, at least %d required", len(secret), MinSecretLength)
+	}
+
+	return &FeedSigner{
+		Param:  DefaultTokenParam,
+		secret: secret,
+	}, nil
+}
+
+// Token - token of `subscriber` valid until `expires`. Zero `expires` never expires
+func (signer *FeedSigner) Token(subscriber string, expires time.Time) string {
+	var exp int64
+	if !expires.IsZero() {
+		exp = expires.Unix()
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(subscriber)) + "." + strconv.FormatInt(exp, 10)
+	return payload + "." + signer.sign(payload)
+}
+
+// Verify - subscriber ID of valid, not expired and not revoked token
+func (signer *FeedSigner) Verify(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrTokenInvalid
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signer.sign(payload))) {
+		return "", ErrTokenInvalid
+	}
+
+	subscriber, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrTokenInvalid
+	}
+
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrTokenInvalid
+	}
+	if exp > 0 && time.Now().Unix() >= exp {
+		return "", ErrTokenExpired
+	}
+
+	if signer.Revoked.IsRevoked(string(subscriber)) || signer.Revoked.IsRevoked(token) {
+		return "", ErrTokenRevoked
+	}
+
+	return string(subscriber), nil
+}
+
+// HMAC of payload
+func (signer *FeedSigner) sign(payload string) string {
+	mac := hmac.New(sha256.New, signer.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// SignURL - `rawURL` with token query parameter
+func (signer *FeedSigner) SignURL(rawURL, token string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || rawURL == "" {
+		return rawURL
+	}
+
+	query := u.Query()
+	query.Set(signer.param(), token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// Verify token of request. Status code of response if token is not valid
+func (signer *FeedSigner) verifyRequest(r *http.Request) (string, int) {
+	token := r.URL.Query().Get(signer.param())
+	if token == "" {
+		return "", http.StatusUnauthorized
+	}
+
+	subscriber, err := signer.Verify(token)
+	if err != nil {
+		return "", http.StatusForbidden
+	}
+	return subscriber, 0
+}
+
+func (signer *FeedSigner) param() string {
+	if signer.Param == "" {
+		return DefaultTokenParam
+	}
+	return signer.Param
+}
+
+// PrivateFeed - copy of fixed feed for subscriber with `token` in self link and enclosure URLs.
+// Private feed is blocked from directories (`itunes:block`) and other platforms (`podcast:locked`).
+func (feed *XMLRoot) PrivateFeed(signer *FeedSigner, token string) *XMLRoot {
+	channel := *feed.Channel
+
+	if !channel.SelfLink.IsEmpty() {
+		selfLink := *channel.SelfLink
+		selfLink.Href = signer.SignURL(selfLink.Href, token)
+		channel.SelfLink = &selfLink
+	}
+
+	channel.ItunesBlock = "Yes"
+	channel.PodcastLocked = &PodcastLocked{Value: "yes"}
+	if channel.ItunesOwner != nil {
+		channel.PodcastLocked.Owner = channel.ItunesOwner.Email
+	}
+
+	channel.Items = make(ItemList, len(feed.Channel.Items))
+	for i, item := range feed.Channel.Items {
+		private := *item
+		if item.Enclosure != nil {
+			enclosure := *item.Enclosure
+			enclosure.URL = signer.SignURL(enclosure.URL, token)
+			private.Enclosure = &enclosure
+
+			// link to episode file
+			if item.Link == item.FileURL {
+				private.Link = signer.SignURL(item.Link, token)
+			}
+		}
+		channel.Items[i] = &private
+	}
+
+	return feed.WithChannel(&channel)
+}
+
+// RevocationList - revoked subscriber IDs and tokens. Safe for concurrent use
+type RevocationList struct {
+	mu      sync.RWMutex
+	entries map[string]bool
+}
+
+// NewRevocationList - list of revoked subscriber IDs or tokens
+func NewRevocationList(entries ...string) *RevocationList {
+	list := &RevocationList{entries: map[string]bool{}}
+	for _, entry := range entries {
+		list.Revoke(entry)
+	}
+	return list
+}
+
+// LoadRevocationList - revoked subscriber IDs or tokens from file, one per line.
+// Empty lines and lines starting with `#` are skipped.
+func LoadRevocationList(fpath string) (*RevocationList, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	list := NewRevocationList()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list.Revoke(line)
+	}
+
+	return list, scanner.Err()
+}
+
+// Revoke - revoke subscriber ID or token
+func (list *RevocationList) Revoke(entry string) {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	list.entries[entry] = true
+}
+
+// IsRevoked - subscriber ID or token is revoked
+func (list *RevocationList) IsRevoked(entry string) bool {
+	if list == nil {
+		return false
+	}
+
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return list.entries[entry]
+}
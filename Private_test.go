@@ -0,0 +1,150 @@
+package podcast
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Signer with `secret` padded to required length
+func newTestSigner(t *testing.T, secret string) *FeedSigner {
+	t.Helper()
+	signer, err := NewFeedSigner([]byte(secret + strings.Repeat("x", MinSecretLength)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestNewFeedSigner(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret []byte
+		valid  bool
+	}{
+		{"nil", nil, false},
+		{"empty", []byte{}, false},
+		{"short", []byte("secret"), false},
+		{"one byte short", make([]byte, MinSecretLength-1), false},
+		{"min length", make([]byte, MinSecretLength), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewFeedSigner(tt.secret)
+			if tt.valid != (err == nil) || tt.valid != (signer != nil) {
+				t.Errorf("expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestFeedSignerVerify(t *testing.T) {
+	signer := newTestSigner(t, "secret")
+	signer.Revoked = NewRevocationList("revoked@example.xx")
+
+	valid := signer.Token("john@example.xx", time.Now().Add(time.Hour))
+	forever := signer.Token("john@example.xx", time.Time{})
+	revokedToken := signer.Token("jane@example.xx", time.Time{})
+	signer.Revoked.Revoke(revokedToken)
+
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name       string
+		token      string
+		subscriber string
+		err        error
+	}{
+		{"valid", valid, "john@example.xx", nil},
+		{"never expires", forever, "john@example.xx", nil},
+		{"expired", signer.Token("john@example.xx", time.Now().Add(-time.Second)), "", ErrTokenExpired},
+		{"other secret", newTestSigner(t, "other").Token("john@example.xx", time.Time{}), "", ErrTokenInvalid},
+		{"tampered subscriber", "amFuZUBleGFtcGxlLnh4." + parts[1] + "." + parts[2], "", ErrTokenInvalid},
+		{"tampered expiry", parts[0] + ".9999999999." + parts[2], "", ErrTokenInvalid},
+		{"tampered signature", parts[0] + "." + parts[1] + ".AAAAAAAAAAAAAAAAAAAAAA", "", ErrTokenInvalid},
+		{"malformed", "not-a-token", "", ErrTokenInvalid},
+		{"empty", "", "", ErrTokenInvalid},
+		{"revoked subscriber", signer.Token("revoked@example.xx", time.Time{}), "", ErrTokenRevoked},
+		{"revoked token", revokedToken, "", ErrTokenRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscriber, err := signer.Verify(tt.token)
+			if err != tt.err || subscriber != tt.subscriber {
+				t.Errorf("expected `%s`, %v; got `%s`, %v", tt.subscriber, tt.err, subscriber, err)
+			}
+		})
+	}
+}
+
+func TestFeedSignerSignURL(t *testing.T) {
+	signer := newTestSigner(t, "secret")
+	signer.Param = "key"
+
+	signed := signer.SignURL("https://example.xx/feed.xml?a=1", "t0k3n")
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("key") != "t0k3n" || u.Query().Get("a") != "1" {
+		t.Errorf("unexpected signed URL `%s`", signed)
+	}
+}
+
+func TestPrivateFeedHandlers(t *testing.T) {
+	signer := newTestSigner(t, "secret")
+	token := signer.Token("john@example.xx", time.Time{})
+	expired := signer.Token("john@example.xx", time.Now().Add(-time.Second))
+
+	fsys := newTestFS(testConfig)
+	feeds := newTestFeedHandler(fsys)
+	feeds.Signer = signer
+
+	p, err := New("podcast.yml", WithFS(fsys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.FixContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	files := NewEnclosureHandler(p)
+	files.Signer = signer
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		target   string
+		expected int
+	}{
+		{"feed without token", feeds, "/feed.xml", http.StatusUnauthorized},
+		{"feed with expired token", feeds, "/feed.xml?token=" + expired, http.StatusForbidden},
+		{"feed with invalid token", feeds, "/feed.xml?token=x.0.y", http.StatusForbidden},
+		{"feed with token", feeds, "/feed.xml?token=" + token, http.StatusOK},
+		{"file without token", files, "/episodes/S01E01.mp3", http.StatusUnauthorized},
+		{"missing file without token", files, "/episodes/S09E09.mp3", http.StatusUnauthorized},
+		{"missing file with invalid token", files, "/episodes/S09E09.mp3?token=x.0.y", http.StatusForbidden},
+		{"file with token", files, "/episodes/S01E01.mp3?token=" + token, http.StatusOK},
+		{"missing file with token", files, "/episodes/S09E09.mp3?token=" + token, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serveTest(tt.handler, tt.target, nil); w.Code != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+
+	w := serveTest(feeds, "/feed.xml?token="+token, nil)
+	body := w.Body.String()
+	if w.Header().Get("X-Robots-Tag") != "noindex" ||
+		!strings.Contains(body, "episodes/S01E01.mp3?token="+token) ||
+		!strings.Contains(body, "<itunes:block>Yes</itunes:block>") {
+		t.Errorf("feed not private:\n%s", body)
+	}
+}
@@ -0,0 +1,55 @@
+package podcast
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+)
+
+var (
+	reFFmpegDuration   = regexp.MustCompile("Duration: ([0-9:]+)")
+	reFFmpegBitrate    = regexp.MustCompile("bitrate: ([0-9]+) kb/s")
+	reExiftoolDuration = regexp.MustCompile("Duration.+?: ([0-9:]+)")
+	reExiftoolBitrate  = regexp.MustCompile("Audio Bitrate.+?: ([0-9]+) kbps")
+)
+
+// Details of media file from external tools
+type mediaInfo struct {
+	Duration Duration
+	Bitrate  int // kbps
+}
+
+// Detect duration and bitrate of local audio file with `ffprobe`, `ffmpeg` or `exiftool`.
+// Zero values if not detected or `ctx` is done.
+func probeMedia(ctx context.Context, fpath string) mediaInfo {
+	var info mediaInfo
+	var buf []byte
+
+	// ffprobe
+	_, buf, _ = runBash(ctx, "ffprobe", fpath)
+	info.parse(buf, reFFmpegDuration, reFFmpegBitrate)
+
+	// fmpeg
+	if info.Duration == 0 && ctx.Err() == nil {
+		_, buf, _ = runBash(ctx, "ffmpeg", "-i", fpath, "2>&1")
+		info.parse(buf, reFFmpegDuration, reFFmpegBitrate)
+	}
+
+	// exiftool
+	if info.Duration == 0 && ctx.Err() == nil {
+		buf, _, _ = runBash(ctx, "exiftool", fpath)
+		info.parse(buf, reExiftoolDuration, reExiftoolBitrate)
+	}
+
+	return info
+}
+
+// Take values from tool output
+func (info *mediaInfo) parse(buf []byte, reDuration, reBitrate *regexp.Regexp) {
+	if matches := reDuration.FindSubmatch(buf); len(matches) >= 2 {
+		info.Duration.Set(string(matches[1]))
+	}
+	if matches := reBitrate.FindSubmatch(buf); len(matches) >= 2 && info.Bitrate == 0 {
+		info.Bitrate, _ = strconv.Atoi(string(matches[1]))
+	}
+}
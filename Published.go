@@ -0,0 +1,205 @@
+package podcast
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Change kinds found by `CheckPublished`
+const (
+	ChangeGUID      = "guid"
+	ChangeEnclosure = "enclosure"
+	ChangeRemoved   = "removed"
+)
+
+// PublishedFeed - episodes of previously published feed
+type PublishedFeed struct {
+	Items []*PublishedItem
+}
+
+// PublishedItem - episode of previously published feed
+type PublishedItem struct {
+	Title        string
+	GUID         string
+	EnclosureURL string
+	Season       int
+	Episode      int
+}
+
+// FeedChange - episode change breaking feeds of subscribers
+type FeedChange struct {
+	Kind  string // `guid`, `enclosure` or `removed`
+	Key   string // empty for removed episode
+	Title string
+	Old   string
+	New   string
+}
+
+func (change *FeedChange) String() string {
+	switch change.Kind {
+	case ChangeGUID:
+		return fmt.Sprintf("Item[%s] GUID changed `%s` ==> `%s`", change.Key, change.Old, change.New)
+	case ChangeEnclosure:
+		return fmt.Sprintf("Item[%s] Enclosure URL changed `%s` ==> `%s`", change.Key, change.Old, change.New)
+	default:
+		return fmt.Sprintf("Episode `%s` (%s) removed", change.Title, change.Old)
+	}
+}
+
+// FeedChanges - all not acknowledged changes versus published feed
+type FeedChanges []*FeedChange
+
+func (changes FeedChanges) Error() string {
+	lines := make([]string, len(changes))
+	for i, change := range changes {
+		lines[i] = change.String()
+	}
+	return strings.Join(lines, "\n") + "\nAcknowledge changes by item key, old GUID or `*`"
+}
+
+// OpenFeed - feed XML from file or `http(s)://` URL.
+// Missing file and `404 Not Found` give error matching `fs.ErrNotExist`.
+func OpenFeed(location string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.Open(location)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	res, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, fmt.Errorf("%s: %w", location, fs.ErrNotExist)
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("%s: %s", location, res.Status)
+	}
+	return res.Body, nil
+}
+
+// LoadPublishedFeed - previously published feed from file or `http(s)://` URL.
+// Missing feed gives error matching `fs.ErrNotExist`.
+func LoadPublishedFeed(location string) (*PublishedFeed, error) {
+	f, err := OpenFeed(location)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	published, err := ParsePublishedFeed(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", location, err)
+	}
+	return published, nil
+}
+
+// ParsePublishedFeed - episodes of RSS feed
+func ParsePublishedFeed(r io.Reader) (*PublishedFeed, error) {
+	channel, err := parseFeedChannel(r)
+	if err != nil {
+		return nil, err
+	}
+
+	published := &PublishedFeed{}
+	for _, node := range channel.children("item") {
+		item := &PublishedItem{
+			Title:        node.childText("title"),
+			GUID:         node.childText("guid"),
+			EnclosureURL: node.childAttr("enclosure", "url"),
+		}
+		item.Season, _ = strconv.Atoi(node.childText("itunes:season"))
+		item.Episode, _ = strconv.Atoi(node.childText("itunes:episode"))
+
+		published.Items = append(published.Items, item)
+	}
+
+	return published, nil
+}
+
+// CheckPublished - changed GUIDs, enclosure URLs and removed episodes versus `published` feed.
+// Episodes are matched by GUID, season and episode number or title.
+// Changes of items given by key or old GUID in `acknowledged` (`*` for all) are allowed.
+// Call after `Fix`.
+func (podcast *Podcast) CheckPublished(published *PublishedFeed, acknowledged ...string) error {
+	if inSlice("*", acknowledged) {
+		return nil
+	}
+
+	items := podcast.Feed.Channel.Items
+	byGUID := map[string]*Item{}
+	byNumber := map[[2]int]*Item{}
+	byTitle := map[string]*Item{}
+	for _, item := range items {
+		if !item.GUID.IsEmpty() {
+			byGUID[item.GUID.Text] = item
+		}
+		if item.Episode > 0 {
+			byNumber[[2]int{item.Season, item.Episode}] = item
+		}
+		byTitle[item.Title] = item
+	}
+
+	var changes FeedChanges
+	for _, old := range published.Items {
+		item := byGUID[old.GUID]
+		if item == nil && old.Episode > 0 {
+			item = byNumber[[2]int{old.Season, old.Episode}]
+		}
+		if item == nil && old.Title != "" {
+			item = byTitle[old.Title]
+		}
+
+		if item == nil {
+			changes = append(changes, &FeedChange{Kind: ChangeRemoved, Title: old.Title, Old: old.GUID})
+			continue
+		}
+
+		if !item.GUID.IsEmpty() && item.GUID.Text != old.GUID {
+			changes = append(changes, &FeedChange{Kind: ChangeGUID, Key: item.Key, Title: item.Title, Old: old.GUID, New: item.GUID.Text})
+		}
+
+		// enclosure prefixes may change, file must stay the same
+		if item.Enclosure != nil && old.EnclosureURL != "" && !sameEnclosure(old.EnclosureURL, item) {
+			changes = append(changes, &FeedChange{Kind: ChangeEnclosure, Key: item.Key, Title: item.Title, Old: old.EnclosureURL, New: item.Enclosure.URL})
+		}
+	}
+
+	var errs FeedChanges
+	for _, change := range changes {
+		if change.Key != "" && inSlice(change.Key, acknowledged) || inSlice(change.Old, acknowledged) {
+			continue
+		}
+		errs = append(errs, change)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Published enclosure URL points to the same file as item (ignoring redirect prefixes).
+// Prefixed URL ends with raw URL after `/`: `op3.dev/e/example.com/a.mp3`
+func sameEnclosure(oldURL string, item *Item) bool {
+	if oldURL == item.Enclosure.URL {
+		return true
+	}
+
+	raw := item.FileURL
+	if raw == "" {
+		raw = item.Enclosure.URL
+	}
+
+	old, raw := trimScheme(oldURL), trimScheme(raw)
+	return old == raw || strings.HasSuffix(old, "/"+raw)
+}
@@ -0,0 +1,66 @@
+package podcast
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSameEnclosure(t *testing.T) {
+	item := &Item{
+		FileURL:   "https://example.com/a.mp3",
+		Enclosure: &Enclosure{URL: "https://op3.dev/e/example.com/a.mp3"},
+	}
+
+	tests := []struct {
+		oldURL   string
+		expected bool
+	}{
+		{"https://op3.dev/e/example.com/a.mp3", true},
+		{"https://example.com/a.mp3", true},
+		{"http://example.com/a.mp3", true},
+		{"https://dts.podtrac.com/redirect.mp3/example.com/a.mp3", true},
+		{"https://notexample.com/a.mp3", false},
+		{"https://op3.dev/e/notexample.com/a.mp3", false},
+		{"https://example.com/b.mp3", false},
+	}
+
+	for _, tt := range tests {
+		if got := sameEnclosure(tt.oldURL, item); got != tt.expected {
+			t.Errorf("sameEnclosure(%q) = %v, expected %v", tt.oldURL, got, tt.expected)
+		}
+	}
+}
+
+func TestCheckPublishedFeedMissing(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		options []Option
+		fails   bool
+	}{
+		{"404", []Option{WithPublishedFeed(srv.URL + "/feed.xml")}, true},
+		{"missing file", []Option{WithPublishedFeed("missing.xml")}, true},
+		{"404 first build", []Option{WithPublishedFeed(srv.URL + "/feed.xml"), WithFirstBuild()}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New("podcast.yml", append(tt.options, WithFS(newTestFS(testConfig)))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = p.checkPublishedFeed()
+			if tt.fails && (err == nil || !strings.HasPrefix(err.Error(), "Published feed:")) {
+				t.Errorf("expected published feed error, got %v", err)
+			}
+			if !tt.fails && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
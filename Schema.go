@@ -0,0 +1,238 @@
+package podcast
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// JSONSchemaVersion - draft of generated schema
+const JSONSchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema - subset of JSON Schema to describe podcast config file
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	PropertyNames        *JSONSchema            `json:"propertyNames,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	OneOf                []*JSONSchema          `json:"oneOf,omitempty"`
+	Definitions          map[string]*JSONSchema `json:"definitions,omitempty"`
+}
+
+// Schemas of types with custom `UnmarshalYAML` (string shortcuts)
+var schemaScalars = map[reflect.Type]*JSONSchema{
+	reflect.TypeOf(CDATA{}): {Type: "string"},
+	reflect.TypeOf(GUID{}):  {Type: "string"},
+	reflect.TypeOf(AttrHref{}): {
+		Type:        "string",
+		Description: "Absolute URL or path relative to `Domain`",
+	},
+	reflect.TypeOf(Image{}): {
+		Type:        "string",
+		Description: "Absolute URL or path relative to `Domain`",
+	},
+	reflect.TypeOf(Owner{}): {
+		Type:        "string",
+		Description: "Comma separated name and email: `John, john@example.xx`",
+		Pattern:     `^[^,]+,\s*[^,\s]+@[^,\s]+$`,
+	},
+	reflect.TypeOf(Category{}): {
+		Type:        "string",
+		Description: "Comma separated category and subcategory: `TV & Film, TV Reviews`. See https://help.apple.com/itc/podcasts_connect/#/itc9267a2f12",
+	},
+	reflect.TypeOf(Date{}): {
+		Type:        "string",
+		Description: "Date `2020-07-14` or date and time `2020-07-14T10:00:00Z`",
+	},
+	reflect.TypeOf(PodcastLocked{}): {
+		Type:        []string{"boolean", "string"},
+		Description: "`yes` forbids importing feed to other hosting platforms",
+	},
+	reflect.TypeOf(Duration(0)): {
+		Type:        []string{"integer", "string"},
+		Description: "Seconds or `HH:MM:SS`, `MM:SS`",
+		Pattern:     `^(\d+|(\d+:)?\d{1,2}:\d{1,2})$`,
+	},
+}
+
+// Accepted values of fields by `Type.Field`
+var schemaEnums = map[string][]string{
+	"Channel.ItunesType":     PodcastTypeValues(),
+	"Channel.ItunesExplicit": ExplicitValues(),
+	"Channel.ItunesBlock":    {"Yes"},
+	"Item.Explicit":          ExplicitValues(),
+	"Item.EpisodeType":       EpisodeTypesValues(),
+	"Season.Explicit":        ExplicitValues(),
+	"Feed.EpisodeTypes":      EpisodeTypesValues(),
+}
+
+var (
+	configSchema     *JSONSchema
+	configSchemaOnce sync.Once
+)
+
+// ConfigSchema - JSON Schema of podcast config file generated from `Channel`
+func ConfigSchema() *JSONSchema {
+	configSchemaOnce.Do(func() {
+		configSchema = newSchemaGenerator().root()
+	})
+	return configSchema
+}
+
+// Schema - JSON Schema of podcast config file.
+// Add to top of podcast YAML file to get validation and autocomplete in editors:
+//
+//	# yaml-language-server: $schema=./podcast.schema.json
+func Schema() ([]byte, error) {
+	return json.MarshalIndent(ConfigSchema(), "", "  ")
+}
+
+type schemaGenerator struct {
+	definitions map[string]*JSONSchema
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		definitions: map[string]*JSONSchema{},
+	}
+}
+
+// Schema of config root: channel with base config file to extend
+func (g *schemaGenerator) root() *JSONSchema {
+	g.ref(reflect.TypeOf(Channel{}))
+
+	// Channel properties are repeated in root as `$ref` can't be extended in draft-07
+	root := *g.definitions["Channel"]
+	root.Schema = JSONSchemaVersion
+	root.Title = "Podcast config"
+	root.Properties = map[string]*JSONSchema{}
+	for name, prop := range g.definitions["Channel"].Properties {
+		root.Properties[name] = prop
+	}
+	root.Properties["Extends"] = &JSONSchema{
+		Type:        "string",
+		Description: "Path to base config file relative to this file",
+	}
+	root.Definitions = g.definitions
+
+	return &root
+}
+
+// Reference to definition of struct type
+func (g *schemaGenerator) ref(t reflect.Type) *JSONSchema {
+	name := t.Name()
+	if _, ok := g.definitions[name]; !ok {
+		def := &JSONSchema{
+			Type:                 "object",
+			Properties:           map[string]*JSONSchema{},
+			AdditionalProperties: false,
+		}
+		// register before fields for recursive types
+		g.definitions[name] = def
+
+		for fieldName, index := range yamlFields(t) {
+			field := t.Field(index)
+			prop := g.schema(field.Type)
+
+			if values, ok := schemaEnums[name+"."+field.Name]; ok {
+				prop = withEnum(prop, values)
+			}
+
+			def.Properties[fieldName] = prop
+		}
+	}
+
+	return &JSONSchema{Ref: "#/definitions/" + name}
+}
+
+// Schema of any type
+func (g *schemaGenerator) schema(t reflect.Type) *JSONSchema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if s, ok := schemaScalars[t]; ok {
+		copied := *s
+		return &copied
+	}
+
+	// inline items, pattern or list of patterns of episode files
+	if t == reflect.TypeOf(ItemList{}) {
+		return &JSONSchema{
+			OneOf: []*JSONSchema{
+				{
+					Type:                 "object",
+					AdditionalProperties: g.ref(reflect.TypeOf(Item{})),
+				},
+				{
+					Type:        "string",
+					Description: "Pattern of episode files: `episodes/*.yml`",
+				},
+				{
+					Type:  "array",
+					Items: &JSONSchema{Type: "string"},
+				},
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.ref(t)
+
+	case reflect.Map:
+		s := &JSONSchema{
+			Type:                 "object",
+			AdditionalProperties: g.schema(t.Elem()),
+		}
+		switch t.Key().Kind() {
+		case reflect.Int, reflect.Int64:
+			s.PropertyNames = &JSONSchema{Pattern: `^[0-9]+$`}
+		}
+		return s
+
+	case reflect.Slice:
+		return &JSONSchema{
+			Type:  "array",
+			Items: g.schema(t.Elem()),
+		}
+
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	}
+
+	return &JSONSchema{Type: "string"}
+}
+
+// Accepted values. Booleans are accepted too as `true` and `false` are not quoted in YAML
+func withEnum(s *JSONSchema, values []string) *JSONSchema {
+	if s.Type == "array" && s.Items != nil {
+		s.Items = withEnum(s.Items, values)
+		return s
+	}
+
+	for _, v := range values {
+		s.Enum = append(s.Enum, v)
+		switch strings.ToLower(v) {
+		case "true", "false":
+			s.Enum = append(s.Enum, v == "true")
+			s.Type = []string{"string", "boolean"}
+		}
+	}
+	return s
+}
@@ -0,0 +1,17 @@
+package podcast
+
+// Season - shared details of all items in the same season.
+// Items inherit these values in `Item.Fix` unless they have their own.
+//
+//	Seasons:
+//	    1:
+//	        Name: The beginning
+//	        Image: /images/season-1.png
+//	        Author: Neo
+type Season struct {
+	Name        string    `yaml:"Name"`
+	Image       *AttrHref `yaml:"Image"`
+	Author      string    `yaml:"Author"`
+	Explicit    string    `yaml:"Explicit"`
+	Description *CDATA    `yaml:"Description"`
+}
@@ -0,0 +1,41 @@
+package podcast
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var reUUID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// PodcastGUIDNamespace - UUID namespace of `podcast:guid`
+// https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#guid
+const PodcastGUIDNamespace = "ead4c236-bf58-58c6-a2c6-a6b28d128cb6"
+
+// PodcastGUID - `podcast:guid` of feed URL: UUIDv5 of URL without scheme and trailing slashes
+func PodcastGUID(feedURL string) string {
+	name := strings.TrimRight(trimScheme(feedURL), "/")
+	guid, _ := uuidV5(PodcastGUIDNamespace, name)
+	return guid
+}
+
+// UUID version 5 (SHA-1) of `name` in `namespace` UUID
+func uuidV5(namespace, name string) (string, error) {
+	ns, err := hex.DecodeString(strings.ReplaceAll(namespace, "-", ""))
+	if err != nil || len(ns) != 16 {
+		return "", fmt.Errorf("invalid UUID `%s`", namespace)
+	}
+
+	hash := sha1.New()
+	hash.Write(ns)
+	hash.Write([]byte(name))
+	u := hash.Sum(nil)[:16]
+
+	u[6] = u[6]&0x0f | 0x50 // version 5
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+
+	s := hex.EncodeToString(u)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32], nil
+}
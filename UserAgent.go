@@ -0,0 +1,123 @@
+package podcast
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sync"
+)
+
+// Rules of known podcast apps, browsers, bots and platforms
+// in format of open podcast user agent list https://github.com/opawg/user-agents
+//
+//go:embed useragents.json
+var userAgentRulesJSON []byte
+
+// UserAgentInfo - client detected from User-Agent
+type UserAgentInfo struct {
+	App      string `json:"app,omitempty"`      // `Apple Podcasts`, `Spotify`, .. Empty if unknown
+	Platform string `json:"platform,omitempty"` // `ios`, `android`, `macos`, .. Empty if unknown
+	Bot      bool   `json:"bot,omitempty"`
+}
+
+// UserAgentRule - user agent patterns of one app, platform or bot
+type UserAgentRule struct {
+	UserAgents []string `json:"user_agents"`
+	App        string   `json:"app,omitempty"`
+	OS         string   `json:"os,omitempty"`
+	Bot        bool     `json:"bot,omitempty"`
+
+	patterns []*regexp.Regexp
+}
+
+// UserAgentClassifier - detects app, platform and bots from User-Agent.
+// Rules are checked in order, the first matching rule with app (or bot) and with os wins.
+type UserAgentClassifier struct {
+	rules []*UserAgentRule
+}
+
+var (
+	defaultClassifier     *UserAgentClassifier
+	defaultClassifierOnce sync.Once
+)
+
+// DefaultUserAgentClassifier - classifier with embedded rules
+func DefaultUserAgentClassifier() *UserAgentClassifier {
+	defaultClassifierOnce.Do(func() {
+		var err error
+		defaultClassifier, err = parseUserAgentRules(userAgentRulesJSON)
+		if err != nil {
+			panic(err)
+		}
+	})
+	return defaultClassifier
+}
+
+// NewUserAgentClassifier - classifier with rules from JSON (e.g. newer version of embedded `useragents.json`)
+func NewUserAgentClassifier(r io.Reader) (*UserAgentClassifier, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseUserAgentRules(buf)
+}
+
+func parseUserAgentRules(buf []byte) (*UserAgentClassifier, error) {
+	var rules []*UserAgentRule
+	if err := json.Unmarshal(buf, &rules); err != nil {
+		return nil, fmt.Errorf("user agent rules: %s", err)
+	}
+
+	for i, rule := range rules {
+		for _, s := range rule.UserAgents {
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return nil, fmt.Errorf("user agent rules[%d]: %s", i, err)
+			}
+			rule.patterns = append(rule.patterns, re)
+		}
+	}
+
+	return &UserAgentClassifier{rules: rules}, nil
+}
+
+// Classify - app, platform and bot status of `userAgent`
+func (c *UserAgentClassifier) Classify(userAgent string) UserAgentInfo {
+	var info UserAgentInfo
+	appFound := false
+
+	for _, rule := range c.rules {
+		if (appFound || rule.App == "" && !rule.Bot) && (info.Platform != "" || rule.OS == "") {
+			continue
+		}
+		if !rule.match(userAgent) {
+			continue
+		}
+
+		if !appFound && (rule.App != "" || rule.Bot) {
+			info.App, info.Bot = rule.App, rule.Bot
+			appFound = true
+		}
+		if info.Platform == "" {
+			info.Platform = rule.OS
+		}
+
+		if appFound && info.Platform != "" {
+			break
+		}
+	}
+
+	return info
+}
+
+func (rule *UserAgentRule) match(userAgent string) bool {
+	for _, re := range rule.patterns {
+		if re.MatchString(userAgent) {
+			return true
+		}
+	}
+	return false
+}
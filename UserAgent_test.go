@@ -0,0 +1,46 @@
+package podcast
+
+import "testing"
+
+func TestUserAgentClassify(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		expected  UserAgentInfo
+	}{
+		// podcast apps before generic browser and OS rules
+		{"Overcast/3.0 (+http://overcast.fm/; iOS podcast app)", UserAgentInfo{App: "Overcast", Platform: "ios"}},
+		{"Podcasts/1555.2.1 CFNetwork/1240.0.4 Darwin/20.5.0", UserAgentInfo{App: "Apple Podcasts"}},
+		{"AppleCoreMedia/1.0.0.18E199 (iPhone; U; CPU OS 14_5 like Mac OS X; en_us)", UserAgentInfo{App: "Apple Podcasts", Platform: "ios"}},
+		{"Spotify/8.6.26 iOS/14.4 (iPhone12,1)", UserAgentInfo{App: "Spotify", Platform: "ios"}},
+		{"Spotify/8.6.42 Android/30 (SM-G991B)", UserAgentInfo{App: "Spotify", Platform: "android"}},
+		{"Pocket Casts", UserAgentInfo{App: "Pocket Casts"}},
+		{"AntennaPod/2.3.2", UserAgentInfo{App: "AntennaPod", Platform: "android"}},
+		{"PodcastAddict/v5 (Linux; U; Android 10; SM-A505F Build/QP1A.190711.020)", UserAgentInfo{App: "Podcast Addict", Platform: "android"}},
+		{"Castro 2021.6/1228 Like iTunes", UserAgentInfo{App: "Castro", Platform: "ios"}},
+		{"AlexaMediaPlayer/2.1.4676.0 (Linux;Android 5.1.1) ExoPlayerLib/1.5.9", UserAgentInfo{App: "Alexa", Platform: "android"}},
+
+		// browsers
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1", UserAgentInfo{App: "Safari", Platform: "ios"}},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15", UserAgentInfo{App: "Safari", Platform: "macos"}},
+		{"Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.120 Mobile Safari/537.36", UserAgentInfo{App: "Chrome", Platform: "android"}},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 Edg/91.0.864.59", UserAgentInfo{App: "Edge", Platform: "windows"}},
+		{"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:89.0) Gecko/20100101 Firefox/89.0", UserAgentInfo{App: "Firefox", Platform: "linux"}},
+
+		// unknown app, known platform
+		{"atc/1.0 watchOS/7.3.3 model/Watch5,4 hwp/t8006 build/18S830 (6; dt:202)", UserAgentInfo{Platform: "watchos"}},
+		{"SomePlayer/1.0", UserAgentInfo{}},
+
+		// bots
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", UserAgentInfo{Bot: true}},
+		{"Feedly/1.0 (+http://www.feedly.com/fetcher.html; 16 subscribers; like FeedFetcher-Google)", UserAgentInfo{Bot: true}},
+		{"curl/7.68.0", UserAgentInfo{Bot: true}},
+		{"", UserAgentInfo{Bot: true}},
+	}
+
+	c := DefaultUserAgentClassifier()
+	for _, tt := range tests {
+		if got := c.Classify(tt.userAgent); got != tt.expected {
+			t.Errorf("Classify(%q) = %+v, expected %+v", tt.userAgent, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Element of any XML document.
+// Names keep namespace prefixes used in feed (`itunes:title`) as struct tags
+// with prefixes can't be used to unmarshal feeds.
+type xmlNode struct {
+	Name     string
+	Attrs    map[string]string
+	Text     string
+	Children []*xmlNode
+}
+
+// Parse XML document into tree of elements
+func parseXMLNode(r io.Reader) (*xmlNode, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+
+	var root *xmlNode
+	var stack []*xmlNode
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{
+				Name:  xmlName(t.Name),
+				Attrs: map[string]string{},
+			}
+			for _, attr := range t.Attr {
+				node.Attrs[xmlName(attr.Name)] = attr.Value
+			}
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+
+		case xml.EndElement:
+			if len(stack) > 0 {
+				node := stack[len(stack)-1]
+				node.Text = strings.TrimSpace(node.Text)
+				stack = stack[:len(stack)-1]
+			}
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return root, nil
+}
+
+// `itunes:title` from raw name
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// The first child element with `name`
+func (node *xmlNode) child(name string) *xmlNode {
+	if node == nil {
+		return nil
+	}
+	for _, child := range node.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// All child elements with `name`
+func (node *xmlNode) children(name string) []*xmlNode {
+	if node == nil {
+		return nil
+	}
+	var nodes []*xmlNode
+	for _, child := range node.Children {
+		if child.Name == name {
+			nodes = append(nodes, child)
+		}
+	}
+	return nodes
+}
+
+// Text of the first child element with `name`
+func (node *xmlNode) childText(name string) string {
+	if child := node.child(name); child != nil {
+		return child.Text
+	}
+	return ""
+}
+
+// Attribute of the first child element with `name`
+func (node *xmlNode) childAttr(name, attr string) string {
+	if child := node.child(name); child != nil {
+		return child.Attrs[attr]
+	}
+	return ""
+}
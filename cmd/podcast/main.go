@@ -0,0 +1,305 @@
+// Command podcast generates podcast feeds from YAML config.
+//
+//	podcast build [-o feed.xml] [-dir public] [-format yaml] [-j 8] [-timeout 5m] [-progress] [-date-from-items] [-uuid-guids] [-published feed.xml] [-ack S01E01,..] [-first-build] [-moved] podcast.yml
+//	podcast schema [-o podcast.schema.json]
+//	podcast diff [-json] [-format yaml] [-uuid-guids] old.xml new.xml|podcast.yml
+//	podcast stats [-config podcast.yml] [-csv] [-min-bytes 0] [-user-agents rules.json] access.log ...
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/briiC/podcast"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "build":
+		err = build(args)
+	case "schema":
+		err = schema(args)
+	case "diff":
+		// exit status 1 is for differences like diff(1)
+		if err := diff(args); err != nil {
+			log.Printf("ERROR: %s", err)
+			os.Exit(2)
+		}
+		return
+	case "stats":
+		err = stats(args)
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("ERROR: %s", err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: podcast <command> [arguments]
+
+Commands:
+  build [-o feed.xml] [-dir public] [-format yaml] [-j 8] [-timeout 5m] [-progress] [-date-from-items] [-uuid-guids] [-published feed.xml] [-ack S01E01,..] [-first-build] [-moved] podcast.yml
+        Generate feed from podcast config. With -dir saves all feeds from 'Feeds:'
+  schema [-o podcast.schema.json]
+        Print JSON Schema of podcast config for editor validation and autocomplete
+  diff [-json] [-format yaml] [-uuid-guids] old.xml new.xml|podcast.yml
+        Show changes between feeds (files, URLs or configs built as by 'build' without writing files)
+        ignoring build dates. Exit status 0 if feeds are the same, 1 if they differ, 2 on error
+  stats [-config podcast.yml] [-csv] [-min-bytes 0] [-user-agents rules.json] access.log ...
+        Count episode downloads in access logs (stdin if no files) per episode, day and app
+`)
+}
+
+// podcast build
+func build(args []string) error {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	output := flags.String("o", "feed.xml", "output file of feed")
+	dir := flags.String("dir", "", "save main feed and all feeds from `Feeds:` into directory")
+	format := flags.String("format", "", "config format: yaml, json or toml (default by file extension)")
+	workers := flags.Int("j", runtime.NumCPU(), "number of episodes processed at the same time")
+	timeout := flags.Duration("timeout", 0, "stop build after given time (e.g. 5m)")
+	progress := flags.Bool("progress", false, "show progress of processed episodes")
+	dateFromItems := flags.Bool("date-from-items", false, "lastBuildDate from the newest episode instead of time of build")
+	uuidGUIDs := flags.Bool("uuid-guids", false, "GUIDs of new episodes as UUIDv5 of podcast:guid and key, pinned in podcast.lock")
+	published := flags.String("published", "", "previously published feed (file or URL) to check GUID and enclosure changes against")
+	ack := flags.String("ack", "", "comma separated item keys or old GUIDs with acknowledged changes (* for all)")
+	firstBuild := flags.Bool("first-build", false, "published feed may be missing (not published yet)")
+	moved := flags.Bool("moved", false, "save minimal redirect feed for old location pointing to NewFeedURL")
+	flags.Parse(args)
+
+	configPath := flags.Arg(0)
+	if configPath == "" {
+		configPath = "podcast.yml"
+	}
+
+	options := []podcast.Option{
+		podcast.WithFormat(*format),
+		podcast.WithWorkers(*workers),
+	}
+	if *published != "" {
+		var acknowledged []string
+		if *ack != "" {
+			acknowledged = strings.Split(*ack, ",")
+		}
+		options = append(options, podcast.WithPublishedFeed(*published, acknowledged...))
+	}
+	if *firstBuild {
+		options = append(options, podcast.WithFirstBuild())
+	}
+	if *moved {
+		options = append(options, podcast.WithMovedFeed())
+	}
+	if *uuidGUIDs {
+		options = append(options, podcast.WithUUIDGUIDs())
+	}
+	if *dateFromItems {
+		options = append(options, podcast.WithBuildDateFromItems())
+	}
+	if *progress {
+		options = append(options, podcast.WithProgress(func(done, total int, item *podcast.Item) {
+			fmt.Fprintf(os.Stderr, "\r[%d/%d] %s\033[K", done, total, item.Key)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}))
+	}
+
+	p, err := podcast.New(configPath, options...)
+	if err != nil {
+		return err
+	}
+
+	// stop on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if *dir != "" {
+		return p.BuildAllContext(ctx, *dir)
+	}
+
+	written, err := p.SaveIfChangedContext(ctx, *output)
+	if err == nil && !written {
+		fmt.Fprintf(os.Stderr, "%s is up to date\n", *output)
+	}
+	return err
+}
+
+// podcast schema
+func schema(args []string) error {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	output := flags.String("o", "", "output file of schema (default stdout)")
+	flags.Parse(args)
+
+	buf, err := podcast.Schema()
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(append(buf, '\n'))
+		return err
+	}
+	return ioutil.WriteFile(*output, append(buf, '\n'), 0644)
+}
+
+// podcast stats
+func stats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	configPath := flags.String("config", "podcast.yml", "podcast config")
+	asCSV := flags.Bool("csv", false, "print report as CSV instead of JSON")
+	minBytes := flags.Int64("min-bytes", 0, "bytes required for download (default 1 minute of audio by bitrate)")
+	userAgents := flags.String("user-agents", "", "user agent rules in JSON (default embedded rules)")
+	flags.Parse(args)
+
+	p, err := podcast.New(*configPath)
+	if err != nil {
+		return err
+	}
+	p.Fix()
+
+	analytics := podcast.NewAnalytics(p)
+	analytics.MinBytes = *minBytes
+
+	if *userAgents != "" {
+		f, err := os.Open(*userAgents)
+		if err != nil {
+			return err
+		}
+		analytics.UserAgents, err = podcast.NewUserAgentClassifier(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if flags.NArg() == 0 {
+		if err := analytics.ReadAccessLog(os.Stdin); err != nil {
+			return err
+		}
+	}
+	for _, fpath := range flags.Args() {
+		f, err := os.Open(fpath)
+		if err != nil {
+			return err
+		}
+		err = analytics.ReadAccessLog(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	report := analytics.Report()
+	if *asCSV {
+		return report.WriteCSV(os.Stdout)
+	}
+	return report.WriteJSON(os.Stdout)
+}
+
+// podcast diff
+func diff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print changes as JSON")
+	format := flags.String("format", "", "config format: yaml, json or toml (default by file extension)")
+	uuidGUIDs := flags.Bool("uuid-guids", false, "build config as with build -uuid-guids")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		return fmt.Errorf("diff requires old and new feed")
+	}
+
+	// config is built as by `build` but cache and lock file are left as they are
+	options := []podcast.Option{
+		podcast.WithFormat(*format),
+		podcast.WithDryRun(),
+	}
+	if *uuidGUIDs {
+		options = append(options, podcast.WithUUIDGUIDs())
+	}
+
+	oldXML, err := feedXML(flags.Arg(0), options)
+	if err != nil {
+		return err
+	}
+	newXML, err := feedXML(flags.Arg(1), options)
+	if err != nil {
+		return err
+	}
+
+	d, err := podcast.DiffFeedXML(bytes.NewReader(oldXML), bytes.NewReader(newXML))
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		err = d.WriteJSON(os.Stdout)
+	} else {
+		err = d.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !d.IsEmpty() {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// Feed XML from file, URL or built from podcast config with `options`
+func feedXML(location string, options []podcast.Option) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(location)) {
+	case ".yml", ".yaml", ".json", ".toml":
+		p, err := podcast.New(location, options...)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.FixContext(context.Background()); err != nil {
+			return nil, err
+		}
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		_, err = p.Feed.WriteTo(&buf)
+		return buf.Bytes(), err
+	}
+
+	f, err := podcast.OpenFeed(location)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
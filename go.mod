@@ -1,8 +1,9 @@
 module github.com/briiC/podcast
 
-go 1.14
+go 1.16
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/dhowden/tag v0.0.0-20200412032933-5d76b8eaae27
 	github.com/fatih/color v1.9.0
 	github.com/gabriel-vasile/mimetype v1.1.1
@@ -11,5 +12,5 @@ require (
 	github.com/mccoyst/vorbis v0.0.0-20190330175025-e010be36d630 // indirect
 	github.com/zhulik/go_mediainfo v0.0.0-20151224204459-29d57b2a6ea0 // indirect
 	golang.org/x/sys v0.0.0-20200722175500-76b94024e4b6 // indirect
-	gopkg.in/yaml.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
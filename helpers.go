@@ -2,8 +2,12 @@ package podcast
 
 import (
 	"bytes"
+	"context"
+	"io"
 	"net/url"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -41,11 +45,11 @@ func isValidURL(URL string) bool {
 	return true
 }
 
-// Execute bash script
-func runBash(name string, args ...string) ([]byte, []byte, error) {
+// Execute bash script. Process is killed when `ctx` is done
+func runBash(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
 	// log.Printf("⍄  %s %s", name, strings.Join(args, " "))
 
-	cmd := exec.Command(name, args...)
+	cmd := exec.CommandContext(ctx, name, args...)
 	bufErrOutput := &bytes.Buffer{}
 	cmd.Stderr = bufErrOutput
 
@@ -61,3 +65,92 @@ func pathToURL(base, path string) string {
 	}
 	return ""
 }
+
+// Wrap URL in redirect prefixes, the first is outermost. Schemes of inner URLs are dropped:
+// `https://example.org/a.mp3` ==> `https://dts.podtrac.com/redirect.mp3/op3.dev/e/example.org/a.mp3`
+func prefixURL(rawURL string, prefixes []string) string {
+	if rawURL == "" || len(prefixes) == 0 {
+		return rawURL
+	}
+
+	s := rawURL
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		s = strings.TrimSuffix(prefixes[i], "/") + "/" + trimScheme(s)
+	}
+	return s
+}
+
+// `https://example.org/a.mp3` ==> `example.org/a.mp3`
+func trimScheme(s string) string {
+	if i := strings.Index(s, "://"); i >= 0 {
+		return s[i+3:]
+	}
+	return s
+}
+
+// Edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j] + 1
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+			if prev[j-1]+cost < cur[j] {
+				cur[j] = prev[j-1] + cost
+			}
+		}
+		prev = cur
+	}
+
+	return prev[len(rb)]
+}
+
+// Write file through temp file in the same directory and rename it over `fpath`.
+// Readers see either old or new file, never half-written one.
+// `replace` decides if written temp file replaces `fpath` (nil replaces always).
+func writeFileAtomic(fpath string, perm os.FileMode, write func(io.Writer) error, replace func(tmpPath string) bool) (bool, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(fpath), "."+filepath.Base(fpath)+".*.tmp")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+
+	if replace != nil && !replace(tmp.Name()) {
+		return false, nil
+	}
+
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp.Name(), fpath); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
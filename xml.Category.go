@@ -16,7 +16,9 @@ func (category *Category) IsEmpty() bool {
 
 // UnmarshalYAML ..
 func (category *Category) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	unmarshal(&category.AttrText)
+	if err := unmarshal(&category.AttrText); err != nil {
+		return err
+	}
 
 	category.AttrText = strings.Trim(category.AttrText, " ,;/")
 	arr := strings.Split(category.AttrText, ",")
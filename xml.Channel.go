@@ -1,7 +1,9 @@
 package podcast
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"log"
 	"net/url"
 	"strings"
@@ -10,10 +12,32 @@ import (
 
 // Channel ..
 type Channel struct {
+	// file system of episode files
+	fsys fs.FS
+
+	// items fixed concurrently by workers
+	workers  int
+	progress ProgressFunc
+
+	// probe results of media files
+	cache *MediaCache
+
+	// time of build and `LastBuildDate` from the newest item
+	clock              func() time.Time
+	buildDateFromItems bool
+
+	// item GUIDs as UUIDv5 of `PodcastGUID` and item key.
+	// Not for items of existing show on the first build with lock file
+	uuidGUIDs    bool
+	keepURLGUIDs bool
+
 	Domain string `xml:"-" yaml:"Domain"`
 
 	SelfLink *AttrHref `xml:"atom:link,omitempty" yaml:"SelfLink"`
 
+	// Feed moved to this URL. Absolute URL or path relative to `Domain`
+	NewFeedURL string `xml:"itunes:new-feed-url,omitempty" yaml:"NewFeedURL"`
+
 	// Text          string    `xml:",chardata" yaml:"-"`
 	Link           string `xml:"link,omitempty" yaml:"Link"`
 	Title          string `xml:"title" yaml:"Title"`
@@ -33,17 +57,55 @@ type Channel struct {
 	ItunesKeywords string    `xml:"itunes:keywords,omitempty" yaml:"Keywords"`
 	ItunesCategory *Category `xml:"itunes:category" yaml:"Category"`
 	ItunesImage    *AttrHref `xml:"itunes:image" yaml:"ItunesImage"`
+	ItunesBlock    string    `xml:"itunes:block,omitempty" yaml:"Block"`
 
 	Country string `xml:"spotify:countryOfOrigin" yaml:"Country"`
 
+	// Forbid importing feed to other hosting platforms
+	PodcastLocked *PodcastLocked `xml:"podcast:locked,omitempty" yaml:"Locked"`
+
+	// Global identifier of podcast. UUIDv5 of `SelfLink` by default
+	PodcastGUID string `xml:"podcast:guid,omitempty" yaml:"PodcastGUID"`
+
 	LastBuildDate *Date  `xml:"lastBuildDate,omitempty" yaml:"LastBuildDate"`
 	Copyright     string `xml:"copyright,omitempty" yaml:"Copyright"`
 
+	// Tracking redirect prefixes of enclosure URLs, the first is outermost:
+	// `https://dts.podtrac.com/redirect.mp3/`, `https://op3.dev/e/`
+	EnclosurePrefixes []string `xml:"-" yaml:"EnclosurePrefixes"`
+
+	// Shared details for items by season number
+	Seasons map[int]*Season `xml:"-" yaml:"Seasons"`
+
+	// Additional feeds derived from this channel
+	Feeds map[string]*Feed `xml:"-" yaml:"Feeds"`
+
 	Items ItemList `xml:"item" yaml:"Items"`
 }
 
+// File system of episode files. Local file system by default
+func (channel *Channel) fs() fs.FS {
+	if channel == nil || channel.fsys == nil {
+		return osFS{}
+	}
+	return channel.fsys
+}
+
+// SeasonInfo - details of season `n` if defined in `Seasons:`
+func (channel *Channel) SeasonInfo(n int) *Season {
+	if channel == nil || n == 0 {
+		return nil
+	}
+	return channel.Seasons[n]
+}
+
 // Fix channel
 func (channel *Channel) Fix() {
+	channel.FixContext(context.Background())
+}
+
+// FixContext - fix channel and its items. Stops processing items when `ctx` is done
+func (channel *Channel) FixContext(ctx context.Context) error {
 
 	// Try to get `Domain` from `Link`
 	if channel.Domain == "" && channel.Link != "" {
@@ -78,8 +140,8 @@ func (channel *Channel) Fix() {
 	}
 
 	// auto add last build time
-	if channel.LastBuildDate == nil || channel.LastBuildDate.IsZero() {
-		channel.LastBuildDate = &Date{time.Now()}
+	if channel.LastBuildDate.IsZero() && !channel.buildDateFromItems {
+		channel.LastBuildDate = &Date{channel.now()}
 	}
 
 	// Init as English podcast by default
@@ -120,15 +182,43 @@ func (channel *Channel) Fix() {
 		channel.ItunesImage = &AttrHref{Href: channel.Image.URL}
 	}
 
+	if !channel.PodcastLocked.IsEmpty() && channel.PodcastLocked.Owner == "" && channel.ItunesOwner != nil {
+		channel.PodcastLocked.Owner = channel.ItunesOwner.Email
+	}
+
 	if !channel.SelfLink.IsEmpty() && !isValidURL(channel.SelfLink.Href) {
 		channel.SelfLink.Href = pathToURL(channel.Domain, channel.SelfLink.Href)
 		channel.SelfLink.Rel = "self"
 		channel.SelfLink.Type = "application/rss+xml"
 	}
 
+	if channel.NewFeedURL != "" && !isValidURL(channel.NewFeedURL) {
+		channel.NewFeedURL = pathToURL(channel.Domain, channel.NewFeedURL)
+	}
+
+	if channel.PodcastGUID == "" && !channel.SelfLink.IsEmpty() {
+		channel.PodcastGUID = PodcastGUID(channel.SelfLink.Href)
+	}
+
+	if channel.uuidGUIDs && !reUUID.MatchString(channel.PodcastGUID) {
+		return fmt.Errorf("UUID GUIDs of items require `SelfLink` or UUID `PodcastGUID`")
+	}
+
 	// Fix items
-	channel.Items.Fix(channel)
+	if err := channel.Items.FixContext(ctx, channel); err != nil {
+		return err
+	}
+
+	// last build time from the newest item
+	if channel.LastBuildDate.IsZero() {
+		if newest := channel.Items.newestPubDate(); !newest.IsZero() {
+			channel.LastBuildDate = &Date{newest}
+		} else {
+			channel.LastBuildDate = &Date{channel.now()}
+		}
+	}
 
+	return nil
 }
 
 // Validate channel
@@ -161,6 +251,25 @@ func (channel *Channel) Validate() error {
 		return fmt.Errorf("Empty Channel Link")
 	}
 
+	if channel.NewFeedURL != "" {
+		if !isValidURL(channel.NewFeedURL) {
+			return fmt.Errorf("NewFeedURL `%s` must be valid URL", channel.NewFeedURL)
+		}
+		if !channel.SelfLink.IsEmpty() && sameFeedURL(channel.NewFeedURL, channel.SelfLink.Href) {
+			return fmt.Errorf("NewFeedURL `%s` must differ from SelfLink", channel.NewFeedURL)
+		}
+	}
+
+	if channel.PodcastGUID != "" && !reUUID.MatchString(channel.PodcastGUID) {
+		return fmt.Errorf("PodcastGUID `%s` must be UUID", channel.PodcastGUID)
+	}
+
+	for _, prefix := range channel.EnclosurePrefixes {
+		if !isValidURL(prefix) {
+			return fmt.Errorf("Enclosure prefix `%s` must be valid URL", prefix)
+		}
+	}
+
 	if channel.ItunesSummary.IsEmpty() {
 		return fmt.Errorf("Empty Channel Summary")
 	}
@@ -13,7 +13,9 @@ func (guid *GUID) IsEmpty() bool {
 
 // UnmarshalYAML ..
 func (guid *GUID) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	unmarshal(&guid.Text)
+	if err := unmarshal(&guid.Text); err != nil {
+		return err
+	}
 
 	guid.IsPermaLink = isValidURL(guid.Text)
 
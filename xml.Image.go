@@ -16,8 +16,7 @@ func (image *Image) IsEmpty() bool {
 
 // UnmarshalYAML ..
 func (image *Image) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	unmarshal(&image.URL)
-	return nil
+	return unmarshal(&image.URL)
 }
 
 // String return URL or Href
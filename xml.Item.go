@@ -1,12 +1,13 @@
 package podcast
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"log"
-	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/gabriel-vasile/mimetype"
 )
@@ -47,10 +48,24 @@ type Item struct {
 	// Different duration formats are accepted however it is recommended to convert the length of the episode into seconds.
 	Duration Duration `xml:"itunes:duration,omitempty" yaml:"Duration"`
 
+	// Season name from `Seasons:` details in channel
+	PodcastSeason *PodcastSeason `xml:"podcast:season,omitempty" yaml:"-"`
+
+	// Used only for filtering items in `Feeds:`
+	Language string   `xml:"-" yaml:"Language"`
+	Tags     []string `xml:"-" yaml:"Tags"`
+
 	File         string `xml:"-" yaml:"File"`
 	FileSize     int64  `xml:"-" yaml:"FileSize"`
 	FileMimeType string `xml:"-" yaml:"FileMimeType"`
 	FileURL      string `xml:"-" yaml:"FileURL"`
+	FileChecksum string `xml:"-" yaml:"FileChecksum"`
+
+	// Audio bitrate in kbps
+	Bitrate int `xml:"-" yaml:"Bitrate"`
+
+	// Overrides `EnclosurePrefixes` of channel. Empty list for no prefixes
+	EnclosurePrefixes []string `xml:"-" yaml:"EnclosurePrefixes"`
 }
 
 // Weight of the item for sorting
@@ -92,8 +107,32 @@ func (item *Item) ExtractKeyInfo() {
 
 // Fix ..
 func (item *Item) Fix() {
+	item.FixContext(context.Background())
+}
+
+// FixContext - fix item. Media probing is stopped when `ctx` is done
+func (item *Item) FixContext(ctx context.Context) error {
 	// log.Printf("Item[%s] Fix()...", item.Key)
 
+	// Inherit missing values from season details
+	if season := item.Channel.SeasonInfo(item.Season); season != nil {
+		if item.Description.IsEmpty() && !season.Description.IsEmpty() {
+			item.Description = &CDATA{Text: season.Description.Text}
+		}
+		if item.ItunesAuthor == "" {
+			item.ItunesAuthor = season.Author
+		}
+		if item.Explicit == "" {
+			item.Explicit = season.Explicit
+		}
+		if item.ItunesImage.IsEmpty() && !season.Image.IsEmpty() {
+			item.ItunesImage = &AttrHref{Href: season.Image.Href}
+		}
+		if season.Name != "" {
+			item.PodcastSeason = &PodcastSeason{Number: item.Season, Name: season.Name}
+		}
+	}
+
 	if item.ContentEncoded.IsEmpty() && !item.Description.IsEmpty() && item.Description != item.ContentEncoded {
 		item.ContentEncoded = item.Description
 	}
@@ -110,18 +149,14 @@ func (item *Item) Fix() {
 		item.ItunesAuthor = item.Channel.ItunesAuthor
 	}
 
-	// Extract information about file
-	if f, err := os.Stat(item.File); !os.IsNotExist(err) {
-		// file size
-		item.FileSize = f.Size()
-		mime, err := mimetype.DetectFile(item.File)
-		if err != nil {
-			log.Printf("Warning: Couldn't get mime type of file `%s`. %s", item.File, err)
-		} else {
-			// fmt.Println(mime.String(), mime.Extension(), err)
-			item.FileMimeType = mime.String()
-		}
+	item.Language = strings.ToLower(item.Language)
+	if item.Language == "" {
+		item.Language = item.Channel.Language
+	}
 
+	// Extract information about file
+	if err := item.probeFile(ctx); err != nil {
+		return err
 	}
 
 	if item.FileURL == "" {
@@ -145,60 +180,111 @@ func (item *Item) Fix() {
 		item.ItunesImage.Href = pathToURL(item.Channel.Domain, item.ItunesImage.Href)
 	}
 
-	// Try detect duration automatically
-	if item.Duration == 0 && item.File != "" {
-		var buf []byte
-		var re *regexp.Regexp
-		var matches [][]byte
-
-		// ffprobe
-		_, buf, _ = runBash("ffprobe", item.File)
-		re = regexp.MustCompile("Duration: ([0-9:]+)")
-		matches = re.FindSubmatch(buf)
-		if len(matches) >= 2 {
-			item.Duration.Set(string(matches[1]))
-		}
-
-		// fmpeg
-		if item.Duration == 0 {
-			_, buf, _ = runBash("ffmpeg", "-i", item.File, "2>&1")
-			re = regexp.MustCompile("Duration: ([0-9:]+)")
-			matches = re.FindSubmatch(buf)
-			if len(matches) >= 2 {
-				item.Duration.Set(string(matches[1]))
-			}
-		}
-
-		// exiftool
-		if item.Duration == 0 {
-			buf, _, _ = runBash("exiftool", item.File)
-			re = regexp.MustCompile("Duration.+?: ([0-9:]+)")
-			matches = re.FindSubmatch(buf)
-			if len(matches) >= 2 {
-				item.Duration.Set(string(matches[1]))
-			}
-		}
-
+	prefixes := item.Channel.EnclosurePrefixes
+	if item.EnclosurePrefixes != nil {
+		prefixes = item.EnclosurePrefixes
 	}
 
 	item.Enclosure = &Enclosure{
-		URL:    item.FileURL,
+		URL:    prefixURL(item.FileURL, prefixes),
 		Length: item.FileSize,
 		Type:   item.FileMimeType,
 	}
 
+	// raw URL so adding or removing prefixes doesn't change GUID
+	if item.GUID.IsEmpty() && item.Channel.uuidGUIDs && !item.Channel.keepURLGUIDs {
+		if guid, err := uuidV5(item.Channel.PodcastGUID, item.Key); err == nil {
+			item.GUID = NewGUID(guid)
+		}
+	}
 	if item.GUID.IsEmpty() {
-		if item.Enclosure != nil {
-			item.GUID = NewGUID(item.Enclosure.URL)
+		if item.FileURL != "" {
+			item.GUID = NewGUID(item.FileURL)
 		} else {
 			item.GUID = NewGUID(item.File)
 		}
 	}
 
 	if item.Link == "" {
-		item.Link = item.Enclosure.URL
+		item.Link = item.FileURL
+	}
+
+	return nil
+}
+
+// Size, mime type, duration, bitrate and checksum of item file.
+// Results are taken from media cache if file not changed.
+func (item *Item) probeFile(ctx context.Context) error {
+	fsys := item.Channel.fs()
+	cache := item.Channel.cache
+
+	f, err := statFile(fsys, item.File)
+	if err != nil || item.File == "" {
+		return nil
 	}
 
+	// file size
+	item.FileSize = f.Size()
+
+	entry := cache.Get(fsys, item.File, f)
+	if entry == nil {
+		entry = &MediaCacheEntry{
+			Size:    f.Size(),
+			ModTime: f.ModTime().UnixNano(),
+		}
+
+		mime, err := detectMimeType(fsys, item.File)
+		if err != nil {
+			log.Printf("Warning: Couldn't get mime type of file `%s`. %s", item.File, err)
+		} else {
+			// fmt.Println(mime.String(), mime.Extension(), err)
+			entry.MimeType = mime.String()
+		}
+
+		if cache != nil && cache.hash {
+			if entry.Checksum, err = fileChecksum(fsys, item.File); err != nil {
+				log.Printf("Warning: Couldn't get checksum of file `%s`. %s", item.File, err)
+			}
+		}
+
+		cache.Set(item.File, entry)
+	}
+
+	// Try detect duration automatically (external tools need local file)
+	if item.Duration == 0 && entry.Duration == 0 && isOSFS(fsys) {
+		info := probeMedia(ctx, item.File)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if info.Duration > 0 {
+			updated := *entry
+			updated.Duration = info.Duration
+			updated.Bitrate = info.Bitrate
+			cache.Set(item.File, &updated)
+			entry = &updated
+		}
+	}
+
+	if entry.MimeType != "" {
+		item.FileMimeType = entry.MimeType
+	}
+	if item.Duration == 0 {
+		item.Duration = entry.Duration
+	}
+	if item.Bitrate == 0 {
+		item.Bitrate = entry.Bitrate
+	}
+	if item.FileChecksum == "" {
+		item.FileChecksum = entry.Checksum
+	}
+
+	// average bitrate from file size
+	if item.Bitrate == 0 && item.Duration > 0 {
+		item.Bitrate = int(item.FileSize * 8 / 1000 / int64(item.Duration))
+	}
+
+	return nil
 }
 
 // Validate channel
@@ -208,7 +294,7 @@ func (item *Item) Validate() error {
 	if item.File == "" {
 		return fmt.Errorf("Item[%s] File path to audio file required", item.Key)
 	}
-	if _, err := os.Stat(item.File); os.IsNotExist(err) {
+	if _, err := statFile(item.Channel.fs(), item.File); err != nil {
 		return fmt.Errorf("Item[%s] %s", item.Key, err)
 	}
 
@@ -260,8 +346,15 @@ func (item *Item) Validate() error {
 		return fmt.Errorf("Item[%s] Enclosure must be valid. Please input valid all of these: `File`, `FileSize`, `FileType`", item.Key)
 	}
 
+	// before enclosure URL built with them
+	for _, prefix := range item.EnclosurePrefixes {
+		if !isValidURL(prefix) {
+			return fmt.Errorf("Item[%s] Enclosure prefix `%s` must be valid URL", item.Key, prefix)
+		}
+	}
+
 	if !isValidURL(item.Enclosure.URL) {
-		return fmt.Errorf("Item[%s] Enclosure URL `%s` not valid. Please enter valid `FileURL`", item.Enclosure.URL, item.Key)
+		return fmt.Errorf("Item[%s] Enclosure URL `%s` not valid. Please enter valid `FileURL`", item.Key, item.Enclosure.URL)
 	}
 
 	if item.Duration == 0 {
@@ -274,3 +367,14 @@ func (item *Item) Validate() error {
 
 	return nil
 }
+
+// Detect mime type of file by its content
+func detectMimeType(fsys fs.FS, fpath string) (*mimetype.MIME, error) {
+	f, err := openFile(fsys, fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return mimetype.DetectReader(f)
+}
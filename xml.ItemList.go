@@ -1,8 +1,13 @@
 package podcast
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 // ItemList ..
@@ -27,7 +32,9 @@ func (items ItemList) Less(i, j int) bool {
 func (items *ItemList) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// unmarshal yaml items into map for easy reading
 	var mItems = map[string]*Item{}
-	unmarshal(&mItems)
+	if err := unmarshal(&mItems); err != nil {
+		return err
+	}
 
 	// populate `items`
 	for key, item := range mItems {
@@ -49,42 +56,164 @@ func (items *ItemList) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// ProgressFunc - called after each item is fixed. `done` of `total` items are fixed
+type ProgressFunc func(done, total int, item *Item)
+
 // Fix ..
 func (items ItemList) Fix(channel *Channel) {
+	items.FixContext(context.Background(), channel)
+}
+
+// FixContext - fix items concurrently with `channel` workers.
+// Stops processing items when `ctx` is done.
+func (items ItemList) FixContext(ctx context.Context, channel *Channel) error {
 	// log.Printf("ItemList Fix()...")
 
+	workers := channel.workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan *Item)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	done := 0
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for item := range jobs {
+				err := item.FixContext(ctx)
+
+				mu.Lock()
+				done++
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if channel.progress != nil {
+					channel.progress(done, len(items), item)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
 	for _, item := range items {
 		item.Channel = channel
-		item.Fix()
+
+		select {
+		case jobs <- item:
+		case <-ctx.Done():
+			break feed
+		}
 	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// ValidationErrors - all problems found while validating
+type ValidationErrors []error
+
+// Error - one error per line
+func (errs ValidationErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
 }
 
 // Validate channel
+// All invalid items and all duplicates are reported together as `ValidationErrors`
 func (items ItemList) Validate() error {
 	// log.Printf("ItemList Validate()...")
 
-	// collect all guids and check if there is no duplicates
-	var guids []string
+	var errs ValidationErrors
 
-	// Weight is season and episode weight (must be unique)
-	var weights []int
+	// GUID, weight (season and episode), enclosure URL and title must be unique
+	guids := newItemGroups()
+	weights := newItemGroups()
+	urls := newItemGroups()
+	titles := newItemGroups()
 
 	for _, item := range items {
 		if err := item.Validate(); err != nil {
-			return err
-		}
-
-		if inSlice(item.GUID.Text, guids) {
-			return fmt.Errorf("GUID must be unique amongst all items. Found duplicate: `%s` (%s)", item.GUID.Text, item.Key)
-		}
-		if inSliceInt(item.Weight(), weights) {
-			return fmt.Errorf("Weight (season, episode) must be unique amongst all items. Found duplicate: `%s` (%s)", item.Key, item.Title)
+			errs = append(errs, err)
+			continue
 		}
 
-		guids = append(guids, item.GUID.Text)
-		weights = append(weights, item.Weight())
+		guids.add(item.GUID.Text, item)
+		weights.add(strconv.Itoa(item.Weight()), item)
+		urls.add(item.Enclosure.URL, item)
+		titles.add(strings.ToLower(strings.TrimSpace(item.Title)), item)
+	}
 
+	for _, key := range guids.duplicates() {
+		errs = append(errs, fmt.Errorf("GUID must be unique amongst all items. Found duplicate: `%s` (%s)", key, guids.keys(key)))
+	}
+	for _, key := range weights.duplicates() {
+		errs = append(errs, fmt.Errorf("Weight (season, episode) must be unique amongst all items. Found duplicate: %s", weights.keys(key)))
+	}
+	for _, key := range urls.duplicates() {
+		errs = append(errs, fmt.Errorf("Enclosure URL must be unique amongst all items. Found duplicate: `%s` (%s)", key, urls.keys(key)))
+	}
+	for _, key := range titles.duplicates() {
+		errs = append(errs, fmt.Errorf("Title must be unique amongst all items. Found duplicate: `%s` (%s)", titles.items[key][0].Title, titles.keys(key)))
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
+
+// Items grouped by some value to find duplicates
+type itemGroups struct {
+	order []string
+	items map[string][]*Item
+}
+
+func newItemGroups() *itemGroups {
+	return &itemGroups{items: map[string][]*Item{}}
+}
+
+// Empty values (optional title) are not grouped
+func (groups *itemGroups) add(key string, item *Item) {
+	if key == "" {
+		return
+	}
+	if _, ok := groups.items[key]; !ok {
+		groups.order = append(groups.order, key)
+	}
+	groups.items[key] = append(groups.items[key], item)
+}
+
+// Values shared by more than one item in order of appearance
+func (groups *itemGroups) duplicates() []string {
+	var keys []string
+	for _, key := range groups.order {
+		if len(groups.items[key]) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// `S01E01, S01E02` - item keys with value
+func (groups *itemGroups) keys(key string) string {
+	keys := make([]string, len(groups.items[key]))
+	for i, item := range groups.items[key] {
+		keys[i] = item.Key
+	}
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,112 @@
+package podcast
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// Channel with `n` valid items
+func newTestChannel(n int) *Channel {
+	fsys := fstest.MapFS{}
+	channel := &Channel{fsys: fsys}
+
+	pubDate := time.Date(2020, 7, 14, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		season, episode := i/1000+1, i%1000+1
+		key := fmt.Sprintf("S%02dE%03d", season, episode)
+		file := "episodes/" + key + ".mp3"
+		url := "https://example.xx/" + file
+		fsys[file] = &fstest.MapFile{Data: []byte("mp3")}
+
+		channel.Items = append(channel.Items, &Item{
+			Channel:      channel,
+			Key:          key,
+			Title:        "Episode " + key,
+			Description:  &CDATA{"Description of " + key},
+			Enclosure:    &Enclosure{URL: url, Length: 3, Type: "audio/mpeg"},
+			Link:         url,
+			GUID:         NewGUID(url),
+			PubDate:      &Date{pubDate.Add(time.Duration(i) * time.Hour)},
+			Season:       season,
+			Episode:      episode,
+			EpisodeType:  EpisodeTypeFull,
+			Explicit:     ExplicitNo,
+			Duration:     Duration(60),
+			File:         file,
+			FileSize:     3,
+			FileMimeType: "audio/mpeg",
+		})
+	}
+
+	return channel
+}
+
+func TestItemListValidateDuplicates(t *testing.T) {
+	items := newTestChannel(5).Items
+	items[1].GUID = NewGUID(items[0].GUID.Text)
+	items[3].Title = items[2].Title
+	items[4].Enclosure.URL = items[2].Enclosure.URL
+
+	err := items.Validate()
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	expected := []string{
+		"GUID must be unique amongst all items. Found duplicate: `https://example.xx/episodes/S01E001.mp3` (S01E001, S01E002)",
+		"Enclosure URL must be unique amongst all items. Found duplicate: `https://example.xx/episodes/S01E003.mp3` (S01E003, S01E005)",
+		"Title must be unique amongst all items. Found duplicate: `Episode S01E003` (S01E003, S01E004)",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d:\n%s", len(expected), len(errs), err)
+	}
+	for i, msg := range expected {
+		if errs[i].Error() != msg {
+			t.Errorf("error %d\nexpected: %s\n     got: %s", i, msg, errs[i])
+		}
+	}
+}
+
+func TestItemListValidateEmptyTitles(t *testing.T) {
+	items := newTestChannel(3).Items
+	for _, item := range items {
+		item.Title = ""
+	}
+
+	if err := items.Validate(); err != nil {
+		t.Fatalf("items without titles must be valid: %s", err)
+	}
+}
+
+func TestItemListValidateInvalidItems(t *testing.T) {
+	items := newTestChannel(3).Items
+	items[0].PubDate = nil
+	items[2].Duration = 0
+
+	err := items.Validate()
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", err)
+	}
+	if !strings.Contains(errs[0].Error(), "S01E001") || !strings.Contains(errs[1].Error(), "S01E003") {
+		t.Errorf("unexpected errors:\n%s", err)
+	}
+}
+
+func BenchmarkItemListValidate(b *testing.B) {
+	items := newTestChannel(10000).Items
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := items.Validate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
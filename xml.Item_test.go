@@ -0,0 +1,102 @@
+package podcast
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestItemEnclosurePrefixes(t *testing.T) {
+	config := strings.Replace(testConfig, "Items:\n", "EnclosurePrefixes:\n    - https://op3.dev/e/\nItems:\n", 1) + `    S01E02:
+        Title: Second
+        Description: Second episode
+        File: episodes/S01E02.mp3
+        PubDate: 2020-07-21
+        Duration: "52:11"
+        EnclosurePrefixes:
+            - https://dts.podtrac.com/redirect.mp3/
+            - https://op3.dev/e/
+    S01E03:
+        Title: Third
+        Description: Third episode
+        File: episodes/S01E03.mp3
+        PubDate: 2020-07-28
+        Duration: "52:11"
+        EnclosurePrefixes: []
+`
+	fsys := newTestFS(config)
+	fsys["episodes/S01E02.mp3"] = &fstest.MapFile{Data: []byte("ID3 episode")}
+	fsys["episodes/S01E03.mp3"] = &fstest.MapFile{Data: []byte("ID3 episode")}
+
+	p, err := New("podcast.yml", WithFS(fsys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.FixContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key       string
+		enclosure string
+	}{
+		{"S01E01", "https://op3.dev/e/example.xx/episodes/S01E01.mp3"},
+		{"S01E02", "https://dts.podtrac.com/redirect.mp3/op3.dev/e/example.xx/episodes/S01E02.mp3"},
+		{"S01E03", "https://example.xx/episodes/S01E03.mp3"},
+	}
+
+	items := map[string]*Item{}
+	for _, item := range p.Episodes() {
+		items[item.Key] = item
+	}
+	for _, tt := range tests {
+		item := items[tt.key]
+		if item == nil {
+			t.Fatalf("Item[%s] not found", tt.key)
+		}
+		if item.Enclosure.URL != tt.enclosure {
+			t.Errorf("Item[%s] expected enclosure `%s`, got `%s`", tt.key, tt.enclosure, item.Enclosure.URL)
+		}
+		// GUID of raw URL
+		if guid := "https://example.xx/episodes/" + tt.key + ".mp3"; item.GUID.Text != guid {
+			t.Errorf("Item[%s] expected GUID `%s`, got `%s`", tt.key, guid, item.GUID.Text)
+		}
+	}
+}
+
+func TestItemEnclosurePrefixesValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes string
+		err      string
+	}{
+		{"valid", "[https://op3.dev/e/]", ""},
+		{"no scheme", "[op3.dev/e/]", "Item[S01E01] Enclosure prefix `op3.dev/e/` must be valid URL"},
+		{"second invalid", "[https://op3.dev/e/, podtrac]", "Item[S01E01] Enclosure prefix `podtrac` must be valid URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := strings.Replace(testConfig, "        Duration: \"52:11\"\n", "        Duration: \"52:11\"\n        EnclosurePrefixes: "+tt.prefixes+"\n", 1)
+			p, err := New("podcast.yml", WithFS(newTestFS(config)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.FixContext(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			err = p.Validate()
+			if tt.err == "" && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Errorf("expected error `%s`, got %v", tt.err, err)
+			}
+		})
+	}
+}
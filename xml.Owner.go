@@ -16,7 +16,9 @@ func (owner *Owner) IsEmpty() bool {
 
 // UnmarshalYAML ..
 func (owner *Owner) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	unmarshal(&owner.Name)
+	if err := unmarshal(&owner.Name); err != nil {
+		return err
+	}
 
 	s := strings.Trim(owner.Name, " ,;/")
 	arr := strings.Split(s, ",")
@@ -0,0 +1,28 @@
+package podcast
+
+// PodcastLocked - <podcast:locked owner="..">yes</podcast:locked>
+// Tells hosting platforms not to import the feed.
+// https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#locked
+type PodcastLocked struct {
+	Value string `xml:",chardata"` // yes or no
+	Owner string `xml:"owner,attr,omitempty"`
+}
+
+// IsEmpty ..
+func (locked *PodcastLocked) IsEmpty() bool {
+	return locked == nil || locked.Value == ""
+}
+
+// UnmarshalYAML ..
+func (locked *PodcastLocked) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var b bool
+	if err := unmarshal(&b); err == nil {
+		locked.Value = "no"
+		if b {
+			locked.Value = "yes"
+		}
+		return nil
+	}
+
+	return unmarshal(&locked.Value)
+}
@@ -0,0 +1,8 @@
+package podcast
+
+// PodcastSeason - <podcast:season name="..">1</podcast:season>
+// https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#season
+type PodcastSeason struct {
+	Number int    `xml:",chardata"`
+	Name   string `xml:"name,attr,omitempty"`
+}
@@ -1,12 +1,20 @@
 package podcast
 
 import (
+	"bytes"
 	"encoding/xml"
+	"io"
 )
 
 // XMLFilePrefix - feed top line
 const XMLFilePrefix = `<?xml version="1.0" encoding="UTF-8"?>`
 
+// Default indentation of feed XML
+const (
+	XMLIndentPrefix = "  "
+	XMLIndent       = "    "
+)
+
 // XMLRoot - rss feed base
 type XMLRoot struct {
 	XMLName       xml.Name `xml:"rss"`
@@ -15,24 +23,89 @@ type XMLRoot struct {
 	Spotify       string   `xml:"xmlns:spotify,attr,omitempty"`
 	Content       string   `xml:"xmlns:content,attr,omitempty"`
 	Atom          string   `xml:"xmlns:atom,attr,omitempty"`
+	Podcast       string   `xml:"xmlns:podcast,attr,omitempty"`
 	Version       string   `xml:"version,attr,omitempty"`
 	Generator     string   `xml:"generator" yaml:"-"`
 	LastBuildDate Date     `xml:"lastBuildDate"`
 	Channel       *Channel `xml:"channel"`
+
+	// indentation of XML, default if not set
+	indentSet    bool
+	indentPrefix string
+	indent       string
+}
+
+// SetIndent - indentation of generated XML. Empty `prefix` and `indent` gives minified XML
+func (feed *XMLRoot) SetIndent(prefix, indent string) {
+	feed.indentSet = true
+	feed.indentPrefix = prefix
+	feed.indent = indent
 }
 
 // ToXML ..
 func (feed *XMLRoot) ToXML(xmlPrefix string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if _, err := feed.writeXML(buf, xmlPrefix); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteTo - stream feed XML into `w`. Items are encoded one by one without building whole document in memory
+func (feed *XMLRoot) WriteTo(w io.Writer) (int64, error) {
+	return feed.writeXML(w, "")
+}
+
+// write XML with `xmlPrefix` top line
+func (feed *XMLRoot) writeXML(w io.Writer, xmlPrefix string) (int64, error) {
 	if xmlPrefix == "" {
 		xmlPrefix = XMLFilePrefix
 	}
 
-	buf, err := xml.MarshalIndent(feed, "  ", "    ")
-	if err != nil {
-		return nil, err
+	prefix, indent := XMLIndentPrefix, XMLIndent
+	if feed.indentSet {
+		prefix, indent = feed.indentPrefix, feed.indent
 	}
 
-	buf = append([]byte(xmlPrefix+"\n"), buf...)
+	cw := &countWriter{w: w}
 
-	return buf, nil
+	// top line on separate line unless minified
+	if prefix != "" || indent != "" {
+		xmlPrefix += "\n"
+	}
+	if _, err := io.WriteString(cw, xmlPrefix); err != nil {
+		return cw.n, err
+	}
+
+	// encoder writes through its own buffer as elements are encoded
+	enc := xml.NewEncoder(cw)
+	enc.Indent(prefix, indent)
+	if err := enc.Encode(feed); err != nil {
+		return cw.n, err
+	}
+
+	return cw.n, nil
+}
+
+// counts written bytes
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// WithChannel - copy of feed with different channel
+func (feed *XMLRoot) WithChannel(channel *Channel) *XMLRoot {
+	root := *feed
+	root.Channel = channel
+	if !channel.LastBuildDate.IsZero() {
+		root.LastBuildDate = *channel.LastBuildDate
+	}
+	return &root
 }